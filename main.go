@@ -15,10 +15,25 @@ func main() {
 
 	// scan LOC for the directory
 	logger.Info("Scanning ", args.LocalScanFilePath, "...")
-	filePaths := scanner.WalkDirectory(args.LocalScanFilePath, args.IgnorePatterns)
-	fileScanResultsArr := []scanner.FileScanResults{}
-	for _, filePath := range filePaths {
-		fileScanResultsArr = append(fileScanResultsArr, scanner.ScanFile(filePath))
+	files := make(chan scanner.FileToScan)
+	go scanner.StreamDirectory(args.LocalScanFilePath, args.IgnorePatterns, files)
+	fileScanResultsArr := scanner.ScanFiles(files, args.Workers)
+	fileScanResultsArr, skippedFiles := report.SplitSkippedFiles(fileScanResultsArr)
+
+	// minified, generated and vendored files are reported separately and only counted in the total if asked for
+	excludedCategories := []string{}
+	if !args.IncludeGenerated {
+		excludedCategories = append(excludedCategories, scanner.CategoryMinified, scanner.CategoryGenerated)
+	}
+	if !args.IncludeVendored {
+		excludedCategories = append(excludedCategories, scanner.CategoryVendored)
+	}
+	fileScanResultsArr, excludedFiles := report.SplitExcludedFiles(fileScanResultsArr, excludedCategories)
+
+	// files with identical content are only counted once
+	duplicateGroups := []report.DuplicateGroup{}
+	if args.Deduplicate {
+		fileScanResultsArr, duplicateGroups = report.RemoveDuplicateFiles(fileScanResultsArr)
 	}
 
 	logger.Debug("Calculating total LOC ...")
@@ -26,9 +41,13 @@ func main() {
 	// sort and calculate total LOC
 	fileScanResultsArr = report.SortFileScanResults(fileScanResultsArr)
 	repoTotalResult := report.CalculateTotalLineOfCode(fileScanResultsArr)
+	mainTotalResult, testTotalResult := report.CalculateMainAndTestLineOfCode(fileScanResultsArr)
 
 	// convert results into records for CSV or command line output
 	records := report.ConvertFileResultsIntoRecords(fileScanResultsArr, repoTotalResult)
+	records = append(records, report.ConvertExcludedFilesIntoRecords(excludedFiles)...)
+	records = append(records, report.ConvertDuplicateGroupsIntoRecords(duplicateGroups)...)
+	records = append(records, report.ConvertSkippedFilesIntoRecords(skippedFiles)...)
 
 	// Dump results by file in a csv
 	if args.CsvFilePath != "" {
@@ -39,7 +58,7 @@ func main() {
 
 	if args.HtmlReportsDirectoryPath != "" {
 		logger.Info("Dumping HTML report to ", args.HtmlReportsDirectoryPath)
-		fileNames, fileContents := report.GenerateHTMLReports(fileScanResultsArr)
+		fileNames, fileContents := report.GenerateHTMLReports(fileScanResultsArr, excludedFiles, duplicateGroups, skippedFiles)
 
 		for index, _ := range fileNames {
 			fileName := fileNames[index]
@@ -51,14 +70,17 @@ func main() {
 	}
 
 	report.PrintResultsToCommandLine(repoTotalResult.CodeLineCount, repoTotalResult.CommentsLineCount, repoTotalResult.BlankLineCount)
+	report.PrintExcludedFilesToCommandLine(excludedFiles)
+	report.PrintDuplicateGroupsToCommandLine(duplicateGroups)
+	report.PrintSkippedFilesToCommandLine(skippedFiles)
 	logger.Info("")
-	logger.Info("VERIFY THIS DOESN'T INCLUDE 3RD PARTY DEPENDENCIES, TEST CODE, AND OTHER NON-SOURCE CODE FILES FROM THIS ANALYSIS.")
+	logger.Info("VERIFY THIS DOESN'T INCLUDE 3RD PARTY DEPENDENCIES OUTSIDE OF VENDORED DIRECTORIES AND OTHER NON-SOURCE CODE FILES FROM THIS ANALYSIS.")
 	logger.Info("")
 	logger.Info("https://docs.sonarsource.com/sonarqube-server/latest/server-upgrade-and-maintenance/monitoring/lines-of-code/ - LOC definitions.")
 	logger.Info("")
 	logger.Info("For detailed reporting, please use the --csv or --html options. ")
-	logger.Info("Total LOC for ", args.LocalScanFilePath, " is ", repoTotalResult.CodeLineCount)
+	logger.Info("Total LOC for ", args.LocalScanFilePath, " is ", repoTotalResult.CodeLineCount, " - main: ", mainTotalResult.CodeLineCount, ", test: ", testTotalResult.CodeLineCount)
 
-	// Print the total LOC to standard output to make it easy for external tools to parse
-	fmt.Println(repoTotalResult.CodeLineCount)
+	// Print the total LOC followed by the main and test LOC to standard output to make it easy for external tools to parse
+	fmt.Printf("%d main=%d test=%d\n", repoTotalResult.CodeLineCount, mainTotalResult.CodeLineCount, testTotalResult.CodeLineCount)
 }
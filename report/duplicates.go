@@ -0,0 +1,109 @@
+package report
+
+import (
+	"go-cloc/logger"
+	"go-cloc/scanner"
+	"sort"
+	"strconv"
+)
+
+// length of the content hash prefix used to identify a group of duplicates in the reports
+const duplicateGroupIdLength = 12
+
+// DuplicateGroup is a set of files with identical content, only the first file is counted in the total
+type DuplicateGroup struct {
+	ContentHash   string
+	FilePaths     []string // the counted file first, followed by its copies
+	CodeLineCount int      // code line count of each copy
+}
+
+// Id returns a short identifier of the group based on the hash of its content
+func (group DuplicateGroup) Id() string {
+	if len(group.ContentHash) < duplicateGroupIdLength {
+		return group.ContentHash
+	}
+	return group.ContentHash[:duplicateGroupIdLength]
+}
+
+// DeduplicatedCodeLineCount returns the lines of code of the copies that are not counted in the total
+func (group DuplicateGroup) DeduplicatedCodeLineCount() int {
+	return group.CodeLineCount * (len(group.FilePaths) - 1)
+}
+
+// RemoveDuplicateFiles keeps one file of each group of files with identical content, the file with the lowest path.
+// Files without a content hash or without code are always kept. Returns the kept files and the groups of duplicates
+// sorted by the lines of code that were de-duplicated in descending order.
+func RemoveDuplicateFiles(fileScanResultsArr []scanner.FileScanResults) ([]scanner.FileScanResults, []DuplicateGroup) {
+	hashToFiles := map[string][]scanner.FileScanResults{}
+	for _, results := range fileScanResultsArr {
+		if results.ContentHash != "" && results.CodeLineCount > 0 {
+			hashToFiles[results.ContentHash] = append(hashToFiles[results.ContentHash], results)
+		}
+	}
+
+	countedFilePaths := map[string]bool{}
+	duplicateGroups := []DuplicateGroup{}
+	for contentHash, files := range hashToFiles {
+		sort.Slice(files, func(a, b int) bool {
+			return files[a].FilePath < files[b].FilePath
+		})
+		countedFilePaths[files[0].FilePath] = true
+		if len(files) == 1 {
+			continue
+		}
+		group := DuplicateGroup{ContentHash: contentHash, CodeLineCount: files[0].CodeLineCount}
+		for _, results := range files {
+			group.FilePaths = append(group.FilePaths, results.FilePath)
+		}
+		duplicateGroups = append(duplicateGroups, group)
+	}
+	sort.Slice(duplicateGroups, func(a, b int) bool {
+		if duplicateGroups[a].DeduplicatedCodeLineCount() != duplicateGroups[b].DeduplicatedCodeLineCount() {
+			return duplicateGroups[a].DeduplicatedCodeLineCount() > duplicateGroups[b].DeduplicatedCodeLineCount()
+		}
+		return duplicateGroups[a].ContentHash < duplicateGroups[b].ContentHash
+	})
+
+	keptFiles := []scanner.FileScanResults{}
+	for _, results := range fileScanResultsArr {
+		if results.ContentHash == "" || results.CodeLineCount == 0 || countedFilePaths[results.FilePath] {
+			keptFiles = append(keptFiles, results)
+		}
+	}
+	return keptFiles, duplicateGroups
+}
+
+// ConvertDuplicateGroupsIntoRecords creates the duplicates section of the CSV, separated from the results by an empty row.
+// Each file of a group is listed with the lines of code de-duplicated from the total, 0 for the file that is counted.
+func ConvertDuplicateGroupsIntoRecords(duplicateGroups []DuplicateGroup) [][]string {
+	if len(duplicateGroups) == 0 {
+		return [][]string{}
+	}
+	records := [][]string{
+		{},
+		{"duplicateGroup", "filePath", "code", "deduplicatedCode"},
+	}
+	for _, group := range duplicateGroups {
+		for index, filePath := range group.FilePaths {
+			deduplicatedCodeLineCount := group.CodeLineCount
+			if index == 0 {
+				deduplicatedCodeLineCount = 0
+			}
+			records = append(records, []string{group.Id(), filePath, strconv.Itoa(group.CodeLineCount), strconv.Itoa(deduplicatedCodeLineCount)})
+		}
+	}
+	return records
+}
+
+// PrintDuplicateGroupsToCommandLine prints the lines of code de-duplicated from the total, the groups are listed at debug level
+func PrintDuplicateGroupsToCommandLine(duplicateGroups []DuplicateGroup) {
+	if len(duplicateGroups) == 0 {
+		return
+	}
+	deduplicatedCodeLineCount := 0
+	for _, group := range duplicateGroups {
+		deduplicatedCodeLineCount += group.DeduplicatedCodeLineCount()
+		logger.Debug("Duplicate group ", group.Id(), " - ", group.DeduplicatedCodeLineCount(), " - ", group.FilePaths)
+	}
+	logger.Info("Duplicates: ", len(duplicateGroups), " groups of files with identical content, ", deduplicatedCodeLineCount, " lines of code not counted in the total")
+}
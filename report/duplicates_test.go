@@ -0,0 +1,59 @@
+package report
+
+import (
+	"go-cloc/scanner"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_duplicates_RemoveDuplicateFiles(t *testing.T) {
+	fileScanResults := []scanner.FileScanResults{
+		{FilePath: "/b/client.js", CodeLineCount: 4, ContentHash: "aaaaaaaaaaaaaaaa"},
+		{FilePath: "/b/index.js", CodeLineCount: 2, ContentHash: "bbbbbbbbbbbbbbbb"},
+		{FilePath: "/a/client.js", CodeLineCount: 4, ContentHash: "aaaaaaaaaaaaaaaa"},
+		{FilePath: "/c/client.js", CodeLineCount: 4, ContentHash: "aaaaaaaaaaaaaaaa"},
+		{FilePath: "/a/__init__.py", CodeLineCount: 0, ContentHash: "cccccccccccccccc"},
+		{FilePath: "/b/__init__.py", CodeLineCount: 0, ContentHash: "cccccccccccccccc"},
+	}
+	keptFiles, duplicateGroups := RemoveDuplicateFiles(fileScanResults)
+
+	// Assert
+	keptFilePaths := []string{}
+	for _, results := range keptFiles {
+		keptFilePaths = append(keptFilePaths, results.FilePath)
+	}
+	assert.Equal(t, []string{"/b/index.js", "/a/client.js", "/a/__init__.py", "/b/__init__.py"}, keptFilePaths)
+	assert.Equal(t, 1, len(duplicateGroups))
+	assert.Equal(t, "aaaaaaaaaaaa", duplicateGroups[0].Id())
+	assert.Equal(t, []string{"/a/client.js", "/b/client.js", "/c/client.js"}, duplicateGroups[0].FilePaths)
+	assert.Equal(t, 8, duplicateGroups[0].DeduplicatedCodeLineCount())
+}
+
+func Test_duplicates_RemoveDuplicateFiles_without_content_hash(t *testing.T) {
+	fileScanResults := []scanner.FileScanResults{
+		{FilePath: "/a/client.js", CodeLineCount: 4},
+		{FilePath: "/b/client.js", CodeLineCount: 4},
+	}
+	keptFiles, duplicateGroups := RemoveDuplicateFiles(fileScanResults)
+
+	// Assert
+	assert.Equal(t, 2, len(keptFiles))
+	assert.Equal(t, 0, len(duplicateGroups))
+}
+
+func Test_duplicates_ConvertDuplicateGroupsIntoRecords(t *testing.T) {
+	duplicateGroups := []DuplicateGroup{
+		{ContentHash: "aaaaaaaaaaaaaaaa", FilePaths: []string{"/a/client.js", "/b/client.js"}, CodeLineCount: 4},
+	}
+	records := ConvertDuplicateGroupsIntoRecords(duplicateGroups)
+
+	// Assert
+	assert.Equal(t, [][]string{
+		{},
+		{"duplicateGroup", "filePath", "code", "deduplicatedCode"},
+		{"aaaaaaaaaaaa", "/a/client.js", "4", "0"},
+		{"aaaaaaaaaaaa", "/b/client.js", "4", "4"},
+	}, records)
+	assert.Equal(t, 0, len(ConvertDuplicateGroupsIntoRecords([]DuplicateGroup{})))
+}
@@ -3,6 +3,7 @@ package report
 import (
 	"go-cloc/logger"
 	"go-cloc/scanner"
+	"html"
 	"os"
 	"path/filepath"
 	"sort"
@@ -15,6 +16,7 @@ type FileTreeComponent struct {
 	children                []*FileTreeComponent
 	name                    string
 	CodeLineCount           int
+	TestCodeLineCount       int            // the part of the code line count that is test code
 	LanguageToCodeLineCount map[string]int // map of language to code line count, empty by default
 }
 
@@ -88,6 +90,7 @@ func createHTMLPage(component *FileTreeComponent) string {
 	htmlContent := "<!DOCTYPE html><html lang='en'><head><meta charset='UTF-8'><style>body{font-family:Arial,sans-serif}.table-container{display:inline-block;margin-right:20px;vertical-align:top}td{padding:8px;border-bottom:1px solid #ddd}th{background-color:#f2f2f2;padding:8px}a{color:#00f;text-decoration:none}a:hover{text-decoration:underline}.code-line-count{padding:8px;border-bottom:1px solid #ddd;text-align:right}.file,.folder{padding:10px;display:inline-block;width:20px;vertical-align:middle}</style><meta name='viewport' content='width=device-width,initial-scale=1'><title>File Tree Report</title></head><body><h1>File Tree Report</h1>"
 	htmlContent += "<p><b>Current Path:</b><a href='" + createUniqueFileNameFromComponentInTree(component.parent) + "'> '" + getFullPathNameFromTree(component, string(filepath.Separator)) + "' </a><span style='color:gray;'>&lAarr; Click to return</span></p>"
 	htmlContent += "<p><b>Total Lines of Code: " + strconv.Itoa(component.CodeLineCount) + "</b></p>"
+	htmlContent += "<p>Main Code: " + strconv.Itoa(component.CodeLineCount-component.TestCodeLineCount) + " - Test Code: " + strconv.Itoa(component.TestCodeLineCount) + "</p>"
 
 	// add file statistics
 	htmlContent += "<div class='table-container'><h2>By File</h2>"
@@ -140,7 +143,7 @@ func combineMapsAndSum(a map[string]int, b map[string]int) map[string]int {
 
 }
 
-// sets the code line count for every component in the tree
+// sets the code line count and test code line count for every component in the tree
 func sumUpTotalLineOfCodeInTree(component *FileTreeComponent) (int, map[string]int) {
 	if component == nil {
 		return 0, map[string]int{}
@@ -151,16 +154,19 @@ func sumUpTotalLineOfCodeInTree(component *FileTreeComponent) (int, map[string]i
 	}
 
 	sum := 0
+	testSum := 0
 	sumLanguageToCodeLineCount := map[string]int{}
 	for _, child := range component.children {
 		sumChildren, languageToCodeLineCount := sumUpTotalLineOfCodeInTree(child)
 		sum += sumChildren
+		testSum += child.TestCodeLineCount
 		sumLanguageToCodeLineCount = combineMapsAndSum(sumLanguageToCodeLineCount, languageToCodeLineCount)
 		logger.Debug("languageToCodeLineCount: ", languageToCodeLineCount)
 	}
 	logger.Debug("Len of children: ", len(component.children))
 	logger.Debug("sumLanguageToCodeLineCount: ", sumLanguageToCodeLineCount)
 	component.CodeLineCount = sum
+	component.TestCodeLineCount = testSum
 	component.LanguageToCodeLineCount = sumLanguageToCodeLineCount
 	return sum, sumLanguageToCodeLineCount
 }
@@ -264,7 +270,15 @@ func createTreeFromScanResults(fileScanResults []scanner.FileScanResults) *FileT
 				// leaf node
 				if j == filePathComponentsLastIndex {
 					newChild.CodeLineCount = result.CodeLineCount
-					newChild.LanguageToCodeLineCount[result.LanguageName] = result.CodeLineCount
+					if result.IsTest {
+						newChild.TestCodeLineCount = result.CodeLineCount
+					}
+					if len(result.LanguageToCodeLineCount) > 0 {
+						// files mixing languages report the code line count of each language
+						newChild.LanguageToCodeLineCount = combineMapsAndSum(newChild.LanguageToCodeLineCount, result.LanguageToCodeLineCount)
+					} else {
+						newChild.LanguageToCodeLineCount[result.LanguageName] = result.CodeLineCount
+					}
 				}
 				addChild(previousComponent, newChild)
 				previousComponent = newChild
@@ -276,7 +290,9 @@ func createTreeFromScanResults(fileScanResults []scanner.FileScanResults) *FileT
 }
 
 // Creates HTML reports to visualize the LoC in the same file structure as was scanned. Helpful for identifying large directories.
-func GenerateHTMLReports(fileScanResults []scanner.FileScanResults) ([]string, []string) {
+// Excluded files are listed on the index page by category, ex: minified, followed by the groups of duplicate files
+// and the skipped files with the reason they were not counted.
+func GenerateHTMLReports(fileScanResults []scanner.FileScanResults, excludedFiles []scanner.FileScanResults, duplicateGroups []DuplicateGroup, skippedFiles []scanner.FileScanResults) ([]string, []string) {
 
 	root := createTreeFromScanResults(fileScanResults)
 
@@ -287,7 +303,67 @@ func GenerateHTMLReports(fileScanResults []scanner.FileScanResults) ([]string, [
 	sortTreeByCodeLineCount(root)
 
 	// generate HTML reports for each file in the tree
-	return generateHTMLReportsForTree(root)
+	fileNames, fileContents := generateHTMLReportsForTree(root)
+	if len(fileContents) > 0 {
+		fileContents[0] += createExcludedFilesHTML(excludedFiles)
+		fileContents[0] += createDuplicatesHTML(duplicateGroups)
+		fileContents[0] += createSkippedFilesHTML(skippedFiles)
+	}
+	return fileNames, fileContents
+}
+
+// creates a table for each excluded category listing its files and their lines of code, empty if no files were excluded
+func createExcludedFilesHTML(excludedFiles []scanner.FileScanResults) string {
+	htmlContent := ""
+	categories, categoryToFiles := groupExcludedFilesByCategory(excludedFiles)
+	for _, category := range categories {
+		files := SortFileScanResults(categoryToFiles[category])
+		htmlContent += "<div class='table-container'><h2>Excluded: " + html.EscapeString(category) + "</h2>"
+		htmlContent += "<table id='excluded-" + html.EscapeString(category) + "-files'><thead><tr><th>File Path</th><th>Language</th><th>Code Line Count</th></tr></thead><tbody>"
+		for _, result := range files {
+			htmlContent += "<tr><td>" + html.EscapeString(result.FilePath) + "</td><td>" + html.EscapeString(result.LanguageName) + "</td><td class='code-line-count'>" + strconv.Itoa(result.CodeLineCount) + "</td></tr>"
+		}
+		htmlContent += "</tbody>"
+		htmlContent += "<tfoot><tr><th></th><th></th><th class='code-line-count'>" + strconv.Itoa(CalculateTotalLineOfCode(files).CodeLineCount) + "</th></tr></tfoot>"
+		htmlContent += "</table></div>"
+	}
+	return htmlContent
+}
+
+// creates a table listing each group of duplicate files and the lines of code de-duplicated, empty if there are no duplicates
+func createDuplicatesHTML(duplicateGroups []DuplicateGroup) string {
+	if len(duplicateGroups) == 0 {
+		return ""
+	}
+	deduplicatedCodeLineCount := 0
+	htmlContent := "<div class='table-container'><h2>Duplicates</h2>"
+	htmlContent += "<table id='duplicate-files'><thead><tr><th>Group</th><th>File Paths</th><th>Code Line Count</th><th>De-duplicated Code Line Count</th></tr></thead><tbody>"
+	for _, group := range duplicateGroups {
+		escapedFilePaths := []string{}
+		for _, filePath := range group.FilePaths {
+			escapedFilePaths = append(escapedFilePaths, html.EscapeString(filePath))
+		}
+		htmlContent += "<tr><td>" + group.Id() + "</td><td>" + strings.Join(escapedFilePaths, "<br>") + "</td><td class='code-line-count'>" + strconv.Itoa(group.CodeLineCount) + "</td><td class='code-line-count'>" + strconv.Itoa(group.DeduplicatedCodeLineCount()) + "</td></tr>"
+		deduplicatedCodeLineCount += group.DeduplicatedCodeLineCount()
+	}
+	htmlContent += "</tbody>"
+	htmlContent += "<tfoot><tr><th></th><th></th><th></th><th class='code-line-count'>" + strconv.Itoa(deduplicatedCodeLineCount) + "</th></tr></tfoot>"
+	htmlContent += "</table></div>"
+	return htmlContent
+}
+
+// creates a table listing the skipped files and why they were skipped, empty if no files were skipped
+func createSkippedFilesHTML(skippedFiles []scanner.FileScanResults) string {
+	if len(skippedFiles) == 0 {
+		return ""
+	}
+	htmlContent := "<div class='table-container'><h2>Skipped Files</h2>"
+	htmlContent += "<table id='skipped-files'><thead><tr><th>File Path</th><th>Reason</th></tr></thead><tbody>"
+	for _, result := range skippedFiles {
+		htmlContent += "<tr><td>" + html.EscapeString(result.FilePath) + "</td><td>" + html.EscapeString(result.SkipReason) + "</td></tr>"
+	}
+	htmlContent += "</tbody></table></div>"
+	return htmlContent
 }
 
 // simple function to write SVGs to files for the HTML reports to use
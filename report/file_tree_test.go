@@ -59,3 +59,29 @@ func Test_file_tree_createTreeFromScanResults(t *testing.T) {
 	assert.Equal(t, "file3.py", file3.name)
 
 }
+
+func Test_file_tree_createTreeFromScanResults_language_breakdown(t *testing.T) {
+	fileScanResults := []scanner.FileScanResults{
+		{FilePath: "/home/app.vue", LanguageName: "Vue", CodeLineCount: 9, LanguageToCodeLineCount: map[string]int{"HTML": 3, "TypeScript": 6}},
+		{FilePath: "/home/main.ts", LanguageName: "TypeScript", CodeLineCount: 10},
+	}
+	root := createTreeFromScanResults(fileScanResults)
+	sumUpTotalLineOfCodeInTree(root)
+
+	// Assert
+	assert.Equal(t, 19, root.CodeLineCount)
+	assert.Equal(t, map[string]int{"HTML": 3, "TypeScript": 16}, root.LanguageToCodeLineCount)
+}
+
+func Test_file_tree_sumUpTotalLineOfCodeInTree_test_code(t *testing.T) {
+	fileScanResults := []scanner.FileScanResults{
+		{FilePath: "/home/file1.go", LanguageName: "Golang", CodeLineCount: 10},
+		{FilePath: "/home/file1_test.go", LanguageName: "Golang", CodeLineCount: 4, IsTest: true},
+	}
+	root := createTreeFromScanResults(fileScanResults)
+	sumUpTotalLineOfCodeInTree(root)
+
+	// Assert
+	assert.Equal(t, 14, root.CodeLineCount)
+	assert.Equal(t, 4, root.TestCodeLineCount)
+}
@@ -5,11 +5,18 @@ import (
 	"go-cloc/logger"
 	"go-cloc/scanner"
 	"os"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
 )
 
+// Scopes of the files in the CSV, test code is reported apart from main code
+const (
+	scopeMain = "main"
+	scopeTest = "test"
+)
+
 type RepoTotal struct {
 	RepositoryId  string
 	CodeLineCount int
@@ -47,21 +54,118 @@ func CalculateTotalLineOfCode(fileScanResultsArr []scanner.FileScanResults) scan
 	return totalResults
 }
 
+// CalculateMainAndTestLineOfCode calculates the totals of the main code and the test code separately
+func CalculateMainAndTestLineOfCode(fileScanResultsArr []scanner.FileScanResults) (scanner.FileScanResults, scanner.FileScanResults) {
+	mainFiles := []scanner.FileScanResults{}
+	testFiles := []scanner.FileScanResults{}
+	for _, results := range fileScanResultsArr {
+		if results.IsTest {
+			testFiles = append(testFiles, results)
+		} else {
+			mainFiles = append(mainFiles, results)
+		}
+	}
+	mainTotalResults := CalculateTotalLineOfCode(mainFiles)
+	mainTotalResults.FilePath = "total main"
+	testTotalResults := CalculateTotalLineOfCode(testFiles)
+	testTotalResults.FilePath = "total test"
+	return mainTotalResults, testTotalResults
+}
+
+// SplitSkippedFiles separates the files that were scanned from the files that were skipped, ex: binary files
+func SplitSkippedFiles(fileScanResultsArr []scanner.FileScanResults) ([]scanner.FileScanResults, []scanner.FileScanResults) {
+	scannedFiles := []scanner.FileScanResults{}
+	skippedFiles := []scanner.FileScanResults{}
+	for _, results := range fileScanResultsArr {
+		if results.SkipReason != "" {
+			skippedFiles = append(skippedFiles, results)
+		} else {
+			scannedFiles = append(scannedFiles, results)
+		}
+	}
+	return scannedFiles, skippedFiles
+}
+
+// SplitExcludedFiles separates the files counted in the total from the files in the excluded categories, ex: minified
+func SplitExcludedFiles(fileScanResultsArr []scanner.FileScanResults, excludedCategories []string) ([]scanner.FileScanResults, []scanner.FileScanResults) {
+	countedFiles := []scanner.FileScanResults{}
+	excludedFiles := []scanner.FileScanResults{}
+	for _, results := range fileScanResultsArr {
+		if results.Category != "" && slices.Contains(excludedCategories, results.Category) {
+			excludedFiles = append(excludedFiles, results)
+		} else {
+			countedFiles = append(countedFiles, results)
+		}
+	}
+	return countedFiles, excludedFiles
+}
+
+// groups the excluded files by category, the categories are sorted by name
+func groupExcludedFilesByCategory(excludedFiles []scanner.FileScanResults) ([]string, map[string][]scanner.FileScanResults) {
+	categoryToFiles := map[string][]scanner.FileScanResults{}
+	categories := []string{}
+	for _, results := range excludedFiles {
+		if _, found := categoryToFiles[results.Category]; !found {
+			categories = append(categories, results.Category)
+		}
+		categoryToFiles[results.Category] = append(categoryToFiles[results.Category], results)
+	}
+	sort.Strings(categories)
+	return categories, categoryToFiles
+}
+
 // OutputCSV writes the results of the scan to a CSV file
 // Returns the total number of lines of code for all files scanned
 func ConvertFileResultsIntoRecords(fileScanResultsArr []scanner.FileScanResults, totalResults scanner.FileScanResults) [][]string {
 	// Create CSV information
 	records := [][]string{
-		{"filePath", "languageName", "blank", "comment", "code"},
+		{"filePath", "languageName", "blank", "comment", "code", "scope", "lineEnding"},
 	}
 
 	for _, results := range fileScanResultsArr {
-		row := []string{results.FilePath, results.LanguageName, strconv.Itoa(results.BlankLineCount), strconv.Itoa(results.CommentsLineCount), strconv.Itoa(results.CodeLineCount)}
+		scope := scopeMain
+		if results.IsTest {
+			scope = scopeTest
+		}
+		row := []string{results.FilePath, results.LanguageName, strconv.Itoa(results.BlankLineCount), strconv.Itoa(results.CommentsLineCount), strconv.Itoa(results.CodeLineCount), scope, results.LineEnding}
 		records = append(records, row)
 	}
-	// Append Total Row
-	totalRow := []string{"total", "", strconv.Itoa(totalResults.BlankLineCount), strconv.Itoa(totalResults.CommentsLineCount), strconv.Itoa(totalResults.CodeLineCount)}
-	records = append(records, totalRow)
+	// Append the main and test total rows followed by the total row
+	mainTotalResults, testTotalResults := CalculateMainAndTestLineOfCode(fileScanResultsArr)
+	mainTotalRow := []string{"total main", "", strconv.Itoa(mainTotalResults.BlankLineCount), strconv.Itoa(mainTotalResults.CommentsLineCount), strconv.Itoa(mainTotalResults.CodeLineCount), scopeMain, ""}
+	testTotalRow := []string{"total test", "", strconv.Itoa(testTotalResults.BlankLineCount), strconv.Itoa(testTotalResults.CommentsLineCount), strconv.Itoa(testTotalResults.CodeLineCount), scopeTest, ""}
+	totalRow := []string{"total", "", strconv.Itoa(totalResults.BlankLineCount), strconv.Itoa(totalResults.CommentsLineCount), strconv.Itoa(totalResults.CodeLineCount), "", ""}
+	records = append(records, mainTotalRow, testTotalRow, totalRow)
+	return records
+}
+
+// ConvertSkippedFilesIntoRecords creates the skipped files section of the CSV, separated from the results by an empty row
+func ConvertSkippedFilesIntoRecords(skippedFiles []scanner.FileScanResults) [][]string {
+	if len(skippedFiles) == 0 {
+		return [][]string{}
+	}
+	records := [][]string{
+		{},
+		{"skippedFilePath", "reason"},
+	}
+	for _, results := range skippedFiles {
+		records = append(records, []string{results.FilePath, results.SkipReason})
+	}
+	return records
+}
+
+// ConvertExcludedFilesIntoRecords creates a CSV section for each excluded category, ex: excluded: minified, with the
+// results of its files and their total. Each section is separated from the previous one by an empty row.
+func ConvertExcludedFilesIntoRecords(excludedFiles []scanner.FileScanResults) [][]string {
+	records := [][]string{}
+	categories, categoryToFiles := groupExcludedFilesByCategory(excludedFiles)
+	for _, category := range categories {
+		files := SortFileScanResults(categoryToFiles[category])
+		sectionRecords := ConvertFileResultsIntoRecords(files, CalculateTotalLineOfCode(files))
+		sectionRecords[0][0] = "excluded: " + category
+		records = append(records, []string{})
+		records = append(records, sectionRecords...)
+	}
 	return records
 }
 
@@ -141,3 +245,26 @@ func formatStringsForColumn(columnEntriesRaw []string) []string {
 	}
 	return columnEntriesFormatted
 }
+
+// PrintSkippedFilesToCommandLine lists the files that were not counted and why
+func PrintSkippedFilesToCommandLine(skippedFiles []scanner.FileScanResults) {
+	if len(skippedFiles) == 0 {
+		return
+	}
+	logger.Info("Skipped ", len(skippedFiles), " files:")
+	for _, results := range skippedFiles {
+		logger.Info(results.FilePath, " - ", results.SkipReason)
+	}
+}
+
+// PrintExcludedFilesToCommandLine prints the lines of code of each excluded category, the files are listed at debug level
+func PrintExcludedFilesToCommandLine(excludedFiles []scanner.FileScanResults) {
+	categories, categoryToFiles := groupExcludedFilesByCategory(excludedFiles)
+	for _, category := range categories {
+		files := categoryToFiles[category]
+		logger.Info("Excluded: ", category, " - ", len(files), " files with ", CalculateTotalLineOfCode(files).CodeLineCount, " lines of code not counted in the total")
+		for _, results := range files {
+			logger.Debug(results.FilePath, " - ", results.CodeLineCount)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package report
+
+import (
+	"go-cloc/scanner"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_report_SplitSkippedFiles(t *testing.T) {
+	fileScanResults := []scanner.FileScanResults{
+		{FilePath: "/home/file1.go", LanguageName: "Golang", CodeLineCount: 10},
+		{FilePath: "/home/blob.cls", SkipReason: scanner.SkipReasonBinary},
+	}
+	scannedFiles, skippedFiles := SplitSkippedFiles(fileScanResults)
+
+	// Assert
+	assert.Equal(t, 1, len(scannedFiles))
+	assert.Equal(t, "/home/file1.go", scannedFiles[0].FilePath)
+	assert.Equal(t, 1, len(skippedFiles))
+	assert.Equal(t, "/home/blob.cls", skippedFiles[0].FilePath)
+}
+
+func Test_report_ConvertSkippedFilesIntoRecords(t *testing.T) {
+	skippedFiles := []scanner.FileScanResults{
+		{FilePath: "/home/blob.cls", SkipReason: scanner.SkipReasonBinary},
+	}
+	records := ConvertSkippedFilesIntoRecords(skippedFiles)
+
+	// Assert
+	assert.Equal(t, [][]string{{}, {"skippedFilePath", "reason"}, {"/home/blob.cls", "binary file"}}, records)
+	assert.Equal(t, 0, len(ConvertSkippedFilesIntoRecords([]scanner.FileScanResults{})))
+}
+
+func Test_report_SplitExcludedFiles(t *testing.T) {
+	fileScanResults := []scanner.FileScanResults{
+		{FilePath: "/home/file1.go", LanguageName: "Golang", CodeLineCount: 10},
+		{FilePath: "/home/app.min.js", LanguageName: "JavaScript", CodeLineCount: 1, Category: scanner.CategoryMinified},
+		{FilePath: "/home/file1_string.go", LanguageName: "Golang", CodeLineCount: 8, Category: scanner.CategoryGenerated},
+	}
+	countedFiles, excludedFiles := SplitExcludedFiles(fileScanResults, []string{scanner.CategoryMinified})
+
+	// Assert
+	assert.Equal(t, 2, len(countedFiles))
+	assert.Equal(t, "/home/file1_string.go", countedFiles[1].FilePath)
+	assert.Equal(t, 1, len(excludedFiles))
+	assert.Equal(t, "/home/app.min.js", excludedFiles[0].FilePath)
+}
+
+func Test_report_ConvertExcludedFilesIntoRecords(t *testing.T) {
+	excludedFiles := []scanner.FileScanResults{
+		{FilePath: "/home/app.min.js", LanguageName: "JavaScript", CodeLineCount: 1, Category: scanner.CategoryMinified},
+		{FilePath: "/home/file1_string.go", LanguageName: "Golang", CodeLineCount: 8, CommentsLineCount: 1, Category: scanner.CategoryGenerated},
+	}
+	records := ConvertExcludedFilesIntoRecords(excludedFiles)
+
+	// Assert
+	assert.Equal(t, [][]string{
+		{},
+		{"excluded: generated", "languageName", "blank", "comment", "code", "scope", "lineEnding"},
+		{"/home/file1_string.go", "Golang", "0", "1", "8", "main", ""},
+		{"total main", "", "0", "1", "8", "main", ""},
+		{"total test", "", "0", "0", "0", "test", ""},
+		{"total", "", "0", "1", "8", "", ""},
+		{},
+		{"excluded: minified", "languageName", "blank", "comment", "code", "scope", "lineEnding"},
+		{"/home/app.min.js", "JavaScript", "0", "0", "1", "main", ""},
+		{"total main", "", "0", "0", "1", "main", ""},
+		{"total test", "", "0", "0", "0", "test", ""},
+		{"total", "", "0", "0", "1", "", ""},
+	}, records)
+	assert.Equal(t, 0, len(ConvertExcludedFilesIntoRecords([]scanner.FileScanResults{})))
+}
+
+func Test_report_ConvertFileResultsIntoRecords_main_and_test(t *testing.T) {
+	fileScanResults := []scanner.FileScanResults{
+		{FilePath: "/home/file1.go", LanguageName: "Golang", BlankLineCount: 1, CodeLineCount: 10, LineEnding: scanner.LineEndingLF},
+		{FilePath: "/home/file1_test.go", LanguageName: "Golang", CommentsLineCount: 2, CodeLineCount: 4, IsTest: true, LineEnding: scanner.LineEndingCRLF},
+	}
+	records := ConvertFileResultsIntoRecords(fileScanResults, CalculateTotalLineOfCode(fileScanResults))
+
+	// Assert
+	assert.Equal(t, [][]string{
+		{"filePath", "languageName", "blank", "comment", "code", "scope", "lineEnding"},
+		{"/home/file1.go", "Golang", "1", "0", "10", "main", "LF"},
+		{"/home/file1_test.go", "Golang", "0", "2", "4", "test", "CRLF"},
+		{"total main", "", "1", "0", "10", "main", ""},
+		{"total test", "", "0", "2", "4", "test", ""},
+		{"total", "", "1", "2", "14", "", ""},
+	}, records)
+}
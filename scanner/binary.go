@@ -0,0 +1,58 @@
+package scanner
+
+import (
+	"bytes"
+	"unicode/utf8"
+)
+
+// number of bytes read from the start of a file to decide if it is binary
+const binarySniffLength = 8000
+
+// content where more than this percentage of bytes are control characters or invalid UTF-8 is binary
+const binaryThresholdPercent = 30
+
+// Reasons a file is skipped instead of being counted
+const (
+	SkipReasonBinary              = "binary file"
+	SkipReasonUnsupportedLanguage = "unsupported language"
+	SkipReasonUnreadable          = "failed to read file"
+	SkipReasonTooLarge            = "larger than the memory limit"
+	SkipReasonInvalidNotebook     = "invalid notebook JSON"
+)
+
+// IsBinary returns true if the start of a file looks like binary content rather than text.
+// Content containing a NUL byte is binary, as is content where more than 30% of the bytes are control characters
+// or invalid UTF-8. The threshold still allows text in legacy encodings, ex: Windows-1252 accented characters.
+func IsBinary(head []byte) bool {
+	if len(head) == 0 {
+		return false
+	}
+	if bytes.IndexByte(head, 0) != -1 {
+		return true
+	}
+
+	suspiciousBytes := 0
+	for i := 0; i < len(head); {
+		r, size := utf8.DecodeRune(head[i:])
+		if r == utf8.RuneError && size == 1 {
+			// a multi-byte character cut off at the end of the block is not suspicious
+			if !utf8.FullRune(head[i:]) {
+				break
+			}
+			suspiciousBytes++
+		} else if r < 0x20 && !isTextControlCharacter(r) {
+			suspiciousBytes++
+		}
+		i += size
+	}
+	return suspiciousBytes*100 > len(head)*binaryThresholdPercent
+}
+
+// control characters commonly found in text files
+func isTextControlCharacter(r rune) bool {
+	switch r {
+	case '\t', '\n', '\r', '\f', '\v', '\b', 0x1a, 0x1b:
+		return true
+	}
+	return false
+}
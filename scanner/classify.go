@@ -0,0 +1,157 @@
+package scanner
+
+import (
+	"bytes"
+	"go-cloc/logger"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Categories of files that are counted but reported apart from the source code
+const (
+	CategoryMinified  = "minified"
+	CategoryGenerated = "generated"
+	CategoryVendored  = "vendored"
+)
+
+// VendoredDirectories are the names of directories containing third-party code, wildcards are supported, ex: *.egg-info
+var VendoredDirectories = []string{
+	"node_modules",
+	"vendor",
+	"third_party",
+	"bower_components",
+	"Pods",
+	".venv",
+	"site-packages",
+	"external",
+}
+
+// conventions for the location and name of test code, matched against the path relative to the scanned directory
+var testFileConventions = []*regexp.Regexp{
+	regexp.MustCompile(`(^|/)(test|tests|__tests__|spec|testFixtures)/`),
+	regexp.MustCompile(`(^|/)src/[a-z]\w*Test/`), // Maven and Gradle source sets, ex: src/integrationTest/
+	regexp.MustCompile(`(^|/)[^/]+_test\.go$`),
+	regexp.MustCompile(`(^|/)[^/]+\.(test|spec)\.[cm]?[jt]sx?$`),
+	regexp.MustCompile(`(^|/)(test_[^/]+|[^/]+_test)\.py$`),
+	regexp.MustCompile(`(^|/)[^/]+_(spec|test)\.rb$`),
+	regexp.MustCompile(`(^|/)[^/]+Tests?\.(java|kt|scala|groovy|cs|swift)$`),
+}
+
+// user supplied patterns identifying test code in addition to the conventions, ex: *IT.java
+var testPatterns = []*regexp.Regexp{}
+
+// a file is minified if its lines are this long on average once indentation is removed
+const minifiedAverageLineLength = 110
+
+// and whitespace makes up less than this percentage of its characters
+const minifiedWhitespacePercent = 10
+
+// a header written by code generators, the pattern is only matched against content containing its text
+type generatedFileMarker struct {
+	text    []byte
+	pattern *regexp.Regexp
+	// the pattern is only matched against the annotations of the first type declared, ex: @Generated public class A
+	onFirstType bool
+}
+
+// the first type declared along with the annotations directly before it, ex: @Generated("protoc")\npublic final class A
+var firstTypeDeclaration = regexp.MustCompile(`(?m)^(\s*@[\w.]+(\([^)]*\))?)*\s*((public|protected|private|internal|abstract|final|static|sealed|open|data)\s+)*(class|interface|enum|record|object)\s+\w`)
+
+// headers written by code generators, ex: // Code generated by protoc-gen-go. DO NOT EDIT.
+var generatedFileMarkers = []generatedFileMarker{
+	{[]byte("Code generated "), regexp.MustCompile(`(?m)^\W*Code generated .*DO NOT EDIT`), false},
+	{[]byte("@generated"), regexp.MustCompile(`(?m)^\W*@generated\b`), false},
+	{[]byte("Generated"), regexp.MustCompile(`@((javax|jakarta)\.annotation\.(processing\.)?)?Generated\b`), true},
+	{[]byte("<auto-generated"), regexp.MustCompile(`<auto-generated\b`), false},
+}
+
+// ClassifyContent returns the category of a file from the start of its content, ex: minified for a bundled app.min.js.
+// Files are generated if they contain a generator header and minified if their lines are long with little whitespace.
+// Source code returns an empty category.
+func ClassifyContent(head []byte) string {
+	for _, marker := range generatedFileMarkers {
+		if !bytes.Contains(head, marker.text) {
+			continue
+		}
+		// an annotation on a single member, ex: Lombok's @Generated equals, does not make the whole file generated
+		content := head
+		if marker.onFirstType {
+			content = firstTypeDeclaration.Find(head)
+		}
+		if marker.pattern.Match(content) {
+			return CategoryGenerated
+		}
+	}
+	if isMinified(head) {
+		return CategoryMinified
+	}
+	return ""
+}
+
+func isMinified(head []byte) bool {
+	lineCount := 0
+	characterCount := 0
+	whitespaceCount := 0
+	for _, line := range bytes.Split(head, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		lineCount++
+		characterCount += len(line)
+		for _, c := range line {
+			if isWhitespace(c) {
+				whitespaceCount++
+			}
+		}
+	}
+	if lineCount == 0 {
+		return false
+	}
+	return characterCount/lineCount > minifiedAverageLineLength && whitespaceCount*100 < characterCount*minifiedWhitespacePercent
+}
+
+// LoadVendoredDirectories replaces the vendored directory names with the ones listed in the file, one per line
+func LoadVendoredDirectories(path string) {
+	VendoredDirectories = ReadIgnoreFile(path)
+	logger.Debug("Vendored directories: ", VendoredDirectories)
+}
+
+// IsVendoredPath returns true if a directory of the path relative to the scanned directory holds third-party code,
+// ex: node_modules/left-pad/index.js. The file name itself is not checked.
+func IsVendoredPath(relativePath string) bool {
+	directories := strings.Split(filepath.ToSlash(filepath.Dir(relativePath)), "/")
+	for _, directory := range directories {
+		for _, pattern := range VendoredDirectories {
+			if matched, _ := filepath.Match(pattern, directory); matched {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// LoadTestPatterns adds the patterns listed in the file, one per line, to the patterns identifying test code
+func LoadTestPatterns(path string) {
+	patterns := ReadIgnoreFile(path)
+	testPatterns = append(testPatterns, loadIgnorePatterns(patterns)...)
+	logger.Debug("Test patterns: ", patterns)
+}
+
+// IsTestPath returns true if the path relative to the scanned directory is test code, ex: src/test/java/AppTest.java.
+// Test code is identified by common conventions and by the patterns loaded with LoadTestPatterns.
+func IsTestPath(relativePath string) bool {
+	relativePath = filepath.ToSlash(relativePath)
+	for _, convention := range testFileConventions {
+		if convention.MatchString(relativePath) {
+			return true
+		}
+	}
+	for _, pattern := range testPatterns {
+		if pattern.MatchString(relativePath) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,56 @@
+package scanner
+
+import (
+	"bytes"
+	"unicode"
+	"unicode/utf8"
+)
+
+// hasColumnRules returns true if the columns of a line have a meaning in the language, ex: fixed-format COBOL
+func hasColumnRules(languageInfo LanguageInfo) bool {
+	return languageInfo.IndicatorColumn > 0 || len(languageInfo.IgnoredColumns) > 0
+}
+
+// applyColumnRules appends the text of the line that is counted to buffer, which is the line without its indicator
+// column and ignored columns, and returns true if the indicator makes the whole line a comment. Columns start at 1
+// and count characters rather than bytes, ex: in COBOL, columns 1 to 6 hold sequence numbers and a * in column 7
+// comments out the line.
+func applyColumnRules(buffer []byte, line []byte, languageInfo LanguageInfo) ([]byte, bool) {
+	isComment := false
+	column := 1
+	for i := 0; i < len(line); column++ {
+		_, size := utf8.DecodeRune(line[i:])
+		if column == languageInfo.IndicatorColumn {
+			for _, indicator := range languageInfo.IndicatorComments {
+				if indicator != "" && hasPrefix(line[i:], indicator) {
+					isComment = true
+				}
+			}
+		} else if !isIgnoredColumn(column, languageInfo.IgnoredColumns) {
+			buffer = append(buffer, line[i:i+size]...)
+		}
+		i += size
+	}
+	return buffer, isComment
+}
+
+// returns true if the column is inside one of the ranges of ignored columns, ex: [[1, 6], [73, 80]]
+func isIgnoredColumn(column int, ignoredColumns [][]int) bool {
+	for _, columns := range ignoredColumns {
+		if len(columns) == 2 && columns[0] <= column && column <= columns[1] {
+			return true
+		}
+	}
+	return false
+}
+
+// returns true if the line starts with a directive switching the rest of the file to free format, ex: **FREE in RPG
+func isFreeFormatDirective(line []byte, languageInfo LanguageInfo) bool {
+	line = bytes.TrimLeftFunc(line, unicode.IsSpace)
+	for _, directive := range languageInfo.FreeFormatDirectives {
+		if directive != "" && len(line) >= len(directive) && equalFoldASCII(line[:len(directive)], directive) {
+			return true
+		}
+	}
+	return false
+}
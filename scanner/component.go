@@ -0,0 +1,151 @@
+package scanner
+
+import (
+	"go-cloc/logger"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// languages whose files are single-file components, split into template, script and style sections
+var singleFileComponentLanguages = map[string]bool{
+	"Vue":    true,
+	"Svelte": true,
+	"Astro":  true,
+}
+
+// languages used for each section of a single-file component when the section does not declare one with lang="..."
+const (
+	componentTemplateLanguage    = "HTML"
+	componentScriptLanguage      = "JavaScript"
+	componentStyleLanguage       = "CSS"
+	componentFrontmatterLanguage = "TypeScript"
+)
+
+// Astro frontmatter is fenced by this line at the very top of the file
+const componentFrontmatterFence = "---"
+
+var componentLangAttributeRegex = regexp.MustCompile(`(?i)\blang\s*=\s*["']?([\w-]+)`)
+
+// a section of a single-file component, scanned with the comment rules of its language
+type componentSection struct {
+	langName   string
+	analyzer   *lineAnalyzer // classifies the lines of the section with the comment rules of its language
+	closingTag string        // ends the section, ex: </script
+}
+
+// ScanSingleFileComponent counts the lines of a Vue, Svelte or Astro component. The component is split into its
+// template, <script> and <style> sections, as well as Astro's --- frontmatter, and each section is scanned with the
+// comment rules of its language, ex: <script lang="ts"> is scanned as TypeScript. The code lines of each section's
+// language are reported in LanguageToCodeLineCount. Lines longer than Options.FileMemoryLimit are truncated.
+func ScanSingleFileComponent(r io.Reader, filePath string, langName string) FileScanResults {
+	result := FileScanResults{
+		FilePath:                filePath,
+		LanguageName:            langName,
+		LanguageToCodeLineCount: map[string]int{},
+	}
+
+	template := newComponentSection(componentTemplateLanguage, "", "")
+	section := template
+
+	reader := newLineReader(r)
+	defer reader.release()
+	isFirstLine := true
+	for {
+		line, err := reader.readLine(Options.FileMemoryLimit)
+		line = strings.TrimSpace(line)
+		lowerCaseLine := strings.ToLower(line)
+
+		switch {
+		case section == template && isFirstLine && line == componentFrontmatterFence:
+			section = newComponentSection(componentFrontmatterLanguage, componentFrontmatterLanguage, componentFrontmatterFence)
+			countComponentLine(&result, Code, section.langName)
+		case section == template && (isComponentTag(lowerCaseLine, "<script") || isComponentTag(lowerCaseLine, "<style")):
+			section = startComponentSection(lowerCaseLine)
+			countComponentLine(&result, Code, section.langName)
+			// the section ends on the same line, ex: <style>a { color: red; }</style>
+			if strings.Contains(lowerCaseLine, section.closingTag) {
+				section = template
+			}
+		case section != template && isComponentSectionEnd(section, line, lowerCaseLine):
+			// the line with the closing tag is part of the section it closes
+			countComponentLine(&result, Code, section.langName)
+			section = template
+		default:
+			section.analyzer.matchPatterns([]byte(line))
+			section.analyzer.analyze([]byte(line), true)
+			countComponentLine(&result, section.analyzer.endLine(), section.langName)
+		}
+		if line != "" {
+			isFirstLine = false
+		}
+
+		if err != nil {
+			// reached end of file
+			if err == io.EOF {
+				break
+			}
+			logger.LogStackTraceAndExit(err)
+		}
+	}
+
+	result.TotalLines = result.CodeLineCount + result.CommentsLineCount + result.BlankLineCount
+	result.LineEnding = reader.lineEnding()
+	return result
+}
+
+func newComponentSection(langName string, fallbackLangName string, closingTag string) *componentSection {
+	languageInfo, found := Languages[langName]
+	if !found {
+		langName = fallbackLangName
+		languageInfo = Languages[fallbackLangName]
+	}
+	return &componentSection{
+		langName:   langName,
+		analyzer:   newLineAnalyzer(languageInfo, LineState{}),
+		closingTag: closingTag,
+	}
+}
+
+// creates the section started by a <script> or <style> tag, using the language of its lang attribute if it is supported
+func startComponentSection(lowerCaseLine string) *componentSection {
+	fallbackLangName, closingTag := componentScriptLanguage, "</script"
+	if isComponentTag(lowerCaseLine, "<style") {
+		fallbackLangName, closingTag = componentStyleLanguage, "</style"
+	}
+
+	langName := fallbackLangName
+	if match := componentLangAttributeRegex.FindStringSubmatch(lowerCaseLine); match != nil {
+		if foundLangName, _, found := lookupLanguage(match[1]); found {
+			langName = foundLangName
+		}
+	}
+	return newComponentSection(langName, fallbackLangName, closingTag)
+}
+
+// returns true if the line starts with the tag, ex: <script setup> starts with <script but <scripts> does not
+func isComponentTag(lowerCaseLine string, tag string) bool {
+	if !strings.HasPrefix(lowerCaseLine, tag) {
+		return false
+	}
+	rest := lowerCaseLine[len(tag):]
+	return rest == "" || rest[0] == '>' || isWhitespace(rest[0])
+}
+
+func isComponentSectionEnd(section *componentSection, line string, lowerCaseLine string) bool {
+	if section.closingTag == componentFrontmatterFence {
+		return line == componentFrontmatterFence
+	}
+	return strings.Contains(lowerCaseLine, section.closingTag)
+}
+
+func countComponentLine(result *FileScanResults, lineResult AnalyzeLineResult, langName string) {
+	if lineResult == Code {
+		result.CodeLineCount++
+		result.LanguageToCodeLineCount[langName]++
+	} else if lineResult == BlankLine {
+		result.BlankLineCount++
+	} else if lineResult == Comment {
+		result.CommentsLineCount++
+	}
+}
@@ -1,194 +1,414 @@
 package scanner
 
 import (
+	"bufio"
 	"bytes"
 	"encoding/json"
 	"fmt"
 	"go-cloc/logger"
 	"io"
 	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
 )
 
 type LanguageInfo struct {
-	LineComments      []string   `json:"LineComments"`
-	MultiLineComments [][]string `json:"MultiLineComments"`
-	Extensions        []string   `json:"Extensions"`
-	FileNames         []string   `json:"FileNames"`
+	LineComments         []string   `json:"LineComments"`
+	MultiLineComments    [][]string `json:"MultiLineComments"`
+	CommentExceptions    []string   `json:"CommentExceptions,omitempty"`    // code starting with a line comment token, ex: PHP's #[ attributes
+	NestedComments       [][]string `json:"NestedComments,omitempty"`       // multi-line comment pairs that can be nested inside of each other
+	StringDelimiters     []string   `json:"StringDelimiters,omitempty"`     // string and character literal delimiters, comment tokens inside them are ignored
+	EscapeCharacter      string     `json:"EscapeCharacter,omitempty"`      // escapes the next character inside a string literal
+	DigitSeparator       string     `json:"DigitSeparator,omitempty"`       // separates the digits of a number literal, it does not start a string literal there, ex: C++'s 1'000
+	DocStrings           []string   `json:"DocStrings,omitempty"`           // multi-line string delimiters, a string literal starting a statement is a docstring
+	StringPrefixes       []string   `json:"StringPrefixes,omitempty"`       // case insensitive prefixes allowed before a docstring delimiter, ex: r"""
+	MultiLineStrings     [][]string `json:"MultiLineStrings,omitempty"`     // string literals spanning lines as [start, end] or [start, end, escape], {delimiter} stands for a custom delimiter, ex: C++'s R"{delimiter}(
+	RegexLiterals        bool       `json:"RegexLiterals,omitempty"`        // a / following an operator or return starts a regular expression literal ending on the same line, ex: /`/g
+	Preprocessor         bool       `json:"Preprocessor,omitempty"`         // the language uses the C preprocessor, whose #if 0 blocks can be counted as comments
+	Heredocs             []string   `json:"Heredocs,omitempty"`             // operators followed by the identifier of a heredoc, which ends on a line starting with the identifier, ex: <<~
+	AmbiguousHeredocs    []string   `json:"AmbiguousHeredocs,omitempty"`    // heredoc operators that are also binary operators, they only start a heredoc on an uppercase or quoted identifier not directly following an operand, ex: Ruby's <<
+	HeredocWhitespace    bool       `json:"HeredocWhitespace,omitempty"`    // spaces are allowed between a heredoc operator and its identifier, ex: cat << EOF
+	IndicatorColumn      int        `json:"IndicatorColumn,omitempty"`      // column of fixed-format lines holding an indicator, ex: 7 for COBOL, never counted
+	IndicatorComments    []string   `json:"IndicatorComments,omitempty"`    // indicators making the whole line a comment, ex: * in the indicator column
+	IgnoredColumns       [][]int    `json:"IgnoredColumns,omitempty"`       // ranges of columns that are never counted, ex: sequence numbers in columns 1 to 6
+	FreeFormatDirectives []string   `json:"FreeFormatDirectives,omitempty"` // a line starting with one of these turns off the column rules for the rest of the file, ex: **FREE
+	LineCommentPatterns  []string   `json:"LineCommentPatterns,omitempty"`  // regular expressions matching a whole line that is a comment, ex: ^[cC*] for Fortran
+	BlankPatterns        []string   `json:"BlankPatterns,omitempty"`        // regular expressions matching a whole line that is counted as blank, ex: ^\s*[{}();]\s*$
+	IgnorePatterns       []string   `json:"IgnorePatterns,omitempty"`       // regular expressions matching a whole line that is not counted at all
+	Extensions           []string   `json:"Extensions"`
+	FileNames            []string   `json:"FileNames"`
+	Interpreters         []string   `json:"Interpreters,omitempty"` // interpreters named by the shebang of files without a suffix, ex: python3
+	Priority             int        `json:"Priority,omitempty"`     // the highest priority wins when several languages claim an extension and no heuristic matches
+
+	patterns *linePatterns // the compiled line patterns, nil if the language has none
+}
+
+// ScanOptions changes how files are scanned, it is set once before scanning starts
+type ScanOptions struct {
+	DocStringsAsCode           bool   // count docstrings as code instead of comments
+	DisabledCodeAsComments     bool   // count the lines disabled by the preprocessor, ex: #if 0, as comments instead of code
+	NotebookMarkdownAsComments bool   // count the markdown cells of Jupyter notebooks as comments instead of ignoring them
+	FallbackEncoding           string // encoding of files that are not valid UTF-8 and have no byte order mark, ex: windows-1252
+	HashContent                bool   // hash the content of every file to find files with identical content
+	FileMemoryLimit            int    // the most bytes of a file held in memory at once, ex: a line of a Vue component
 }
 
+// DefaultFileMemoryLimit is the memory limit used when none is specified, 64 MB
+const DefaultFileMemoryLimit = 64 * 1024 * 1024
+
+// Options used when scanning every file
+var Options = ScanOptions{FileMemoryLimit: DefaultFileMemoryLimit}
+
 var Languages = map[string]LanguageInfo{
 	"ActionScript": {
 		LineComments:      []string{"//"},
 		MultiLineComments: [][]string{{"/*", "*/"}},
+		StringDelimiters:  []string{"\"", "'"},
+		EscapeCharacter:   "\\",
 		Extensions:        []string{".as"},
 		FileNames:         []string{},
 	},
 	"Abap": {
 		LineComments:      []string{"\""},
 		MultiLineComments: [][]string{{"/*", "*/"}},
+		StringDelimiters:  []string{"'", "`"},
 		Extensions:        []string{".abap", ".ab4", ".flow"},
 		FileNames:         []string{},
 	},
 	"Apex": {
 		LineComments:      []string{"//"},
 		MultiLineComments: [][]string{{"/*", "*/"}},
+		StringDelimiters:  []string{"'"},
+		EscapeCharacter:   "\\",
 		Extensions:        []string{".cls", ".trigger"},
 		FileNames:         []string{},
 	},
 	"C": {
 		LineComments:      []string{"//"},
 		MultiLineComments: [][]string{{"/*", "*/"}},
+		StringDelimiters:  []string{"\"", "'"},
+		EscapeCharacter:   "\\",
+		DigitSeparator:    "'",
+		Preprocessor:      true,
 		Extensions:        []string{".c"},
 		FileNames:         []string{},
 	},
 	"C Header": {
 		LineComments:      []string{"//"},
 		MultiLineComments: [][]string{{"/*", "*/"}},
+		StringDelimiters:  []string{"\"", "'"},
+		EscapeCharacter:   "\\",
+		DigitSeparator:    "'",
+		Preprocessor:      true,
 		Extensions:        []string{".h"},
 		FileNames:         []string{},
 	},
 	"C++": {
 		LineComments:      []string{"//"},
 		MultiLineComments: [][]string{{"/*", "*/"}},
+		StringDelimiters:  []string{"\"", "'"},
+		EscapeCharacter:   "\\",
+		DigitSeparator:    "'",
+		MultiLineStrings:  [][]string{{"R\"{delimiter}(", "){delimiter}\""}},
+		Preprocessor:      true,
 		Extensions:        []string{".cpp", ".cc", ".cxx", ".c++"},
 		FileNames:         []string{},
 	},
 	"C++ Header": {
 		LineComments:      []string{"//"},
 		MultiLineComments: [][]string{{"/*", "*/"}},
-		Extensions:        []string{".hh", ".hpp", ".hxx", ".h++", ".ipp"},
+		StringDelimiters:  []string{"\"", "'"},
+		EscapeCharacter:   "\\",
+		DigitSeparator:    "'",
+		MultiLineStrings:  [][]string{{"R\"{delimiter}(", "){delimiter}\""}},
+		Preprocessor:      true,
+		Extensions:        []string{".hh", ".hpp", ".hxx", ".h++", ".ipp", ".h"},
 		FileNames:         []string{},
 	},
 	"COBOL": {
-		LineComments:      []string{"*", "/"},
-		MultiLineComments: [][]string{},
-		Extensions:        []string{".cbl", ".ccp", ".cob", ".cobol", ".cpy"},
-		FileNames:         []string{},
+		LineComments:         []string{"*>"},
+		MultiLineComments:    [][]string{},
+		StringDelimiters:     []string{"\"", "'"},
+		IndicatorColumn:      7,
+		IndicatorComments:    []string{"*", "/"},
+		IgnoredColumns:       [][]int{{1, 6}, {73, 80}},
+		FreeFormatDirectives: []string{">>SOURCE FORMAT FREE", ">>SOURCE FORMAT IS FREE", ">>SOURCE FREE", ">>SOURCE IS FREE", "$SET SOURCEFORMAT\"FREE\"", "$SET SOURCEFORMAT \"FREE\"", "$SET SOURCEFORMAT(FREE)"},
+		Extensions:           []string{".cbl", ".ccp", ".cob", ".cobol", ".cpy"},
+		FileNames:            []string{},
 	},
 	"C#": {
 		LineComments:      []string{"//"},
 		MultiLineComments: [][]string{{"/*", "*/"}},
+		StringDelimiters:  []string{"\"", "'"},
+		EscapeCharacter:   "\\",
+		MultiLineStrings:  [][]string{{"\"\"\"", "\"\"\""}, {"@\"", "\"", "\""}, {"@$\"", "\"", "\""}},
 		Extensions:        []string{".cs"},
 		FileNames:         []string{},
 	},
 	"CSS": {
 		LineComments:      []string{"//"},
 		MultiLineComments: [][]string{{"/*", "*/"}},
+		StringDelimiters:  []string{"\"", "'"},
+		EscapeCharacter:   "\\",
 		Extensions:        []string{".css"},
 		FileNames:         []string{},
 	},
+	"D": {
+		LineComments:      []string{"//"},
+		MultiLineComments: [][]string{{"/*", "*/"}},
+		NestedComments:    [][]string{{"/+", "+/"}},
+		StringDelimiters:  []string{"\"", "'"},
+		EscapeCharacter:   "\\",
+		Extensions:        []string{".d"},
+		FileNames:         []string{},
+	},
 	"Golang": {
 		LineComments:      []string{"//"},
 		MultiLineComments: [][]string{{"/*", "*/"}},
+		StringDelimiters:  []string{"\"", "'"},
+		EscapeCharacter:   "\\",
+		MultiLineStrings:  [][]string{{"`", "`"}},
 		Extensions:        []string{".go"},
 		FileNames:         []string{},
 	},
+	"Haskell": {
+		LineComments:      []string{"--"},
+		MultiLineComments: [][]string{},
+		NestedComments:    [][]string{{"{-", "-}"}},
+		StringDelimiters:  []string{"\""},
+		EscapeCharacter:   "\\",
+		Extensions:        []string{".hs"},
+		FileNames:         []string{},
+	},
 	"HTML": {
 		LineComments:      []string{},
 		MultiLineComments: [][]string{{"<!--", "-->"}},
-		Extensions:        []string{".html", ".htm", ".cshtml", ".vbhtml", ".aspx", ".ascx", ".rhtml", ".erb", ".shtml", ".shtm", ".cmp"},
+		Extensions:        []string{".html", ".htm", ".vbhtml", ".aspx", ".ascx", ".rhtml", ".erb", ".shtml", ".shtm", ".cmp"},
 		FileNames:         []string{},
 	},
 	"Java": {
 		LineComments:      []string{"//"},
 		MultiLineComments: [][]string{{"/*", "*/"}},
+		StringDelimiters:  []string{"\"", "'"},
+		EscapeCharacter:   "\\",
+		MultiLineStrings:  [][]string{{"\"\"\"", "\"\"\"", "\\"}},
 		Extensions:        []string{".java", ".jav"},
 		FileNames:         []string{},
 	},
 	"JavaScript": {
 		LineComments:      []string{"//"},
 		MultiLineComments: [][]string{{"/*", "*/"}},
+		StringDelimiters:  []string{"\"", "'"},
+		EscapeCharacter:   "\\",
+		MultiLineStrings:  [][]string{{"`", "`", "\\"}},
+		RegexLiterals:     true,
 		Extensions:        []string{".js", ".jsx", ".jsp", ".jspx", ".jspf", ".mjs"},
 		FileNames:         []string{},
+		Interpreters:      []string{"node", "nodejs"},
+	},
+	"Jupyter Notebook": {
+		LineComments:      []string{},
+		MultiLineComments: [][]string{},
+		Extensions:        []string{".ipynb"},
+		FileNames:         []string{},
 	},
 	"Kotlin": {
 		LineComments:      []string{"//"},
-		MultiLineComments: [][]string{{"/*", "*/"}},
+		MultiLineComments: [][]string{},
+		NestedComments:    [][]string{{"/*", "*/"}},
+		StringDelimiters:  []string{"\"", "'"},
+		EscapeCharacter:   "\\",
+		MultiLineStrings:  [][]string{{"\"\"\"", "\"\"\""}},
 		Extensions:        []string{".kt", ".kts"},
 		FileNames:         []string{},
 	},
+	"LaTeX": {
+		LineComments:      []string{"%"},
+		MultiLineComments: [][]string{},
+		Extensions:        []string{".tex", ".sty", ".cls"},
+		FileNames:         []string{},
+	},
 	"Flex": {
 		LineComments:      []string{"//"},
 		MultiLineComments: [][]string{{"/*", "*/"}},
+		StringDelimiters:  []string{"\"", "'"},
+		EscapeCharacter:   "\\",
 		Extensions:        []string{".as"},
 		FileNames:         []string{},
 	},
 	"PHP": {
 		LineComments:      []string{"//", "#"},
 		MultiLineComments: [][]string{{"/*", "*/"}},
+		CommentExceptions: []string{"#["},
+		StringDelimiters:  []string{"\"", "'"},
+		EscapeCharacter:   "\\",
+		Heredocs:          []string{"<<<"},
 		Extensions:        []string{".php", ".php3", ".php4", ".php5", ".phtml", ".inc"},
 		FileNames:         []string{},
+		Interpreters:      []string{"php"},
 	},
 	"Objective-C": {
 		LineComments:      []string{"//"},
 		MultiLineComments: [][]string{{"/*", "*/"}},
+		StringDelimiters:  []string{"\"", "'"},
+		EscapeCharacter:   "\\",
+		Preprocessor:      true,
+		Extensions:        []string{".m", ".h"},
+		FileNames:         []string{},
+	},
+	"OCaml": {
+		LineComments:      []string{},
+		MultiLineComments: [][]string{},
+		NestedComments:    [][]string{{"(*", "*)"}},
+		StringDelimiters:  []string{"\""},
+		EscapeCharacter:   "\\",
+		Extensions:        []string{".ml", ".mli"},
+		FileNames:         []string{},
+	},
+	"MATLAB": {
+		LineComments:      []string{"%"},
+		MultiLineComments: [][]string{{"%{", "%}"}},
+		StringDelimiters:  []string{"\""},
 		Extensions:        []string{".m"},
 		FileNames:         []string{},
+		Priority:          -1,
 	},
 	"Oracle PL/SQL": {
 		LineComments:      []string{"--"},
 		MultiLineComments: [][]string{{"/*", "*/"}},
+		StringDelimiters:  []string{"'", "\""},
 		Extensions:        []string{".pkb"},
 		FileNames:         []string{},
 	},
+	"Pascal": {
+		LineComments:      []string{"//"},
+		MultiLineComments: [][]string{{"{", "}"}, {"(*", "*)"}},
+		StringDelimiters:  []string{"'"},
+		Extensions:        []string{".pas", ".pp", ".dpr", ".lpr", ".inc"},
+		FileNames:         []string{},
+	},
 	"PL/I": {
 		LineComments:      []string{"--"},
 		MultiLineComments: [][]string{{"/*", "*/"}},
+		StringDelimiters:  []string{"'", "\""},
 		Extensions:        []string{".pl1"},
 		FileNames:         []string{},
 	},
 	"Python": {
 		LineComments:      []string{"#"},
-		MultiLineComments: [][]string{{"\"\"\"", "\"\"\""}},
-		Extensions:        []string{".py", ".python", ".ipynb"},
+		MultiLineComments: [][]string{},
+		StringDelimiters:  []string{"\"", "'"},
+		EscapeCharacter:   "\\",
+		DocStrings:        []string{"\"\"\"", "'''"},
+		StringPrefixes:    []string{"r", "u", "f", "b", "br", "rb", "fr", "rf"},
+		Extensions:        []string{".py", ".python"},
 		FileNames:         []string{},
+		Interpreters:      []string{"python", "python2", "python3"},
 	},
 
 	"RPG": {
-		LineComments:      []string{"#"},
-		MultiLineComments: [][]string{},
-		Extensions:        []string{".rpg"},
+		LineComments:         []string{"//"},
+		MultiLineComments:    [][]string{},
+		StringDelimiters:     []string{"'"},
+		IndicatorColumn:      7,
+		IndicatorComments:    []string{"*"},
+		IgnoredColumns:       [][]int{{1, 5}, {81, 100}},
+		FreeFormatDirectives: []string{"**FREE"},
+		Extensions:           []string{".rpg", ".rpgle", ".sqlrpgle", ".sqlrpg", ".rpgleinc"},
+		FileNames:            []string{},
+	},
+	"Razor": {
+		LineComments:      []string{"//"},
+		MultiLineComments: [][]string{{"@*", "*@"}, {"<!--", "-->"}, {"/*", "*/"}},
+		StringDelimiters:  []string{"\"", "'"},
+		Extensions:        []string{".cshtml", ".razor"},
 		FileNames:         []string{},
 	},
 	"Ruby": {
 		LineComments:      []string{"#"},
 		MultiLineComments: [][]string{{"=begin", "=end"}},
+		StringDelimiters:  []string{"\"", "'"},
+		EscapeCharacter:   "\\",
+		Heredocs:          []string{"<<~", "<<-", "<<"},
+		AmbiguousHeredocs: []string{"<<"},
 		Extensions:        []string{".rb"},
 		FileNames:         []string{},
+		Interpreters:      []string{"ruby"},
+	},
+	"Rust": {
+		LineComments:      []string{"//"},
+		MultiLineComments: [][]string{},
+		NestedComments:    [][]string{{"/*", "*/"}},
+		StringDelimiters:  []string{"\""},
+		EscapeCharacter:   "\\",
+		MultiLineStrings:  [][]string{{"r{delimiter}\"", "\"{delimiter}"}},
+		Extensions:        []string{".rs"},
+		FileNames:         []string{},
 	},
 	"Scala": {
 		LineComments:      []string{"//"},
-		MultiLineComments: [][]string{{"/*", "*/"}},
+		MultiLineComments: [][]string{},
+		NestedComments:    [][]string{{"/*", "*/"}},
+		StringDelimiters:  []string{"\"", "'"},
+		EscapeCharacter:   "\\",
+		MultiLineStrings:  [][]string{{"\"\"\"", "\"\"\""}},
 		Extensions:        []string{".scala"},
 		FileNames:         []string{},
 	},
 	"Scss": {
 		LineComments:      []string{"//"},
 		MultiLineComments: [][]string{{"/*", "*/"}},
+		StringDelimiters:  []string{"\"", "'"},
+		EscapeCharacter:   "\\",
 		Extensions:        []string{".scss"},
 		FileNames:         []string{},
 	},
+	"Shell": {
+		LineComments:      []string{"#"},
+		MultiLineComments: [][]string{},
+		StringDelimiters:  []string{"\"", "'"},
+		EscapeCharacter:   "\\",
+		Heredocs:          []string{"<<-", "<<"},
+		HeredocWhitespace: true,
+		Extensions:        []string{".sh", ".bash", ".zsh", ".ksh"},
+		FileNames:         []string{},
+		Interpreters:      []string{"sh", "bash", "zsh", "ksh", "dash", "ash"},
+	},
 	"SQL": {
 		LineComments:      []string{"--"},
 		MultiLineComments: [][]string{{"/*", "*/"}},
+		StringDelimiters:  []string{"'", "\""},
 		Extensions:        []string{".sql"},
 		FileNames:         []string{},
 	},
 	"Swift": {
 		LineComments:      []string{"//"},
-		MultiLineComments: [][]string{{"/*", "*/"}},
+		MultiLineComments: [][]string{},
+		NestedComments:    [][]string{{"/*", "*/"}},
+		StringDelimiters:  []string{"\""},
+		EscapeCharacter:   "\\",
+		MultiLineStrings:  [][]string{{"\"\"\"", "\"\"\"", "\\"}},
 		Extensions:        []string{".swift"},
 		FileNames:         []string{},
 	},
 	"TypeScript": {
 		LineComments:      []string{"//"},
 		MultiLineComments: [][]string{{"/*", "*/"}},
+		StringDelimiters:  []string{"\"", "'"},
+		EscapeCharacter:   "\\",
+		MultiLineStrings:  [][]string{{"`", "`", "\\"}},
+		RegexLiterals:     true,
 		Extensions:        []string{".ts", ".tsx"},
 		FileNames:         []string{},
+		Interpreters:      []string{"ts-node", "deno"},
 	},
 	"T-SQL": {
 		LineComments:      []string{"--"},
-		MultiLineComments: [][]string{},
+		MultiLineComments: [][]string{{"/*", "*/"}},
+		StringDelimiters:  []string{"'", "\""},
 		Extensions:        []string{".tsql"},
 		FileNames:         []string{},
 	},
@@ -201,6 +421,7 @@ var Languages = map[string]LanguageInfo{
 	"Visual Basic .NET": {
 		LineComments:      []string{"'"},
 		MultiLineComments: [][]string{},
+		StringDelimiters:  []string{"\""},
 		Extensions:        []string{".vb"},
 		FileNames:         []string{},
 	},
@@ -210,6 +431,18 @@ var Languages = map[string]LanguageInfo{
 		Extensions:        []string{".xml", ".XML", ".xsd", ".xsl"},
 		FileNames:         []string{},
 	},
+	"Svelte": {
+		LineComments:      []string{},
+		MultiLineComments: [][]string{{"<!--", "-->"}},
+		Extensions:        []string{".svelte"},
+		FileNames:         []string{},
+	},
+	"Astro": {
+		LineComments:      []string{},
+		MultiLineComments: [][]string{{"<!--", "-->"}},
+		Extensions:        []string{".astro"},
+		FileNames:         []string{},
+	},
 	"XHTML": {
 		LineComments:      []string{"<!--"},
 		MultiLineComments: [][]string{{"<!--", "-->"}},
@@ -223,14 +456,17 @@ var Languages = map[string]LanguageInfo{
 		FileNames:         []string{},
 	},
 	"Terraform": {
-		LineComments:      []string{},
-		MultiLineComments: [][]string{},
+		LineComments:      []string{"#", "//"},
+		MultiLineComments: [][]string{{"/*", "*/"}},
+		StringDelimiters:  []string{"\""},
+		EscapeCharacter:   "\\",
 		Extensions:        []string{".tf"},
 		FileNames:         []string{},
 	},
 	"JCL": {
-		LineComments:      []string{"//"},
-		MultiLineComments: [][]string{{"/*", "*/"}},
+		LineComments:      []string{"//*"},
+		MultiLineComments: [][]string{},
+		IgnoredColumns:    [][]int{{73, 80}},
 		Extensions:        []string{".jcl", ".JCL"},
 		FileNames:         []string{},
 	},
@@ -247,11 +483,44 @@ var Languages = map[string]LanguageInfo{
 @ext should match exactly as above, ".java" etc.
 */
 func LookupByExtension(ext string) (string, LanguageInfo, bool) {
+	// when several languages claim the extension the one with the highest priority is returned, see LookupByExtensionAndContent
+	candidates := languagesClaimingExtension(ext)
+	if len(candidates) == 0 {
+		return "", LanguageInfo{}, false
+	}
+	return candidates[0], Languages[candidates[0]], true
+}
+
+// returns the names of every language claiming the extension, sorted by priority and then by name so the order is deterministic
+func languagesClaimingExtension(ext string) []string {
+	candidates := []string{}
 	for lang, info := range Languages {
-		for _, languageExt := range info.Extensions {
-			if languageExt == ext {
-				return lang, info, true
-			}
+		if slices.Contains(info.Extensions, ext) {
+			candidates = append(candidates, lang)
+		}
+	}
+	sort.Slice(candidates, func(a, b int) bool {
+		priorityA, priorityB := Languages[candidates[a]].Priority, Languages[candidates[b]].Priority
+		if priorityA != priorityB {
+			return priorityA > priorityB
+		}
+		return candidates[a] < candidates[b]
+	})
+	return candidates
+}
+
+// lookupLanguage finds a language by a short name, ex: "ts" or "typescript" both find TypeScript.
+// The name is first looked up as an extension and then as a case insensitive language name.
+func lookupLanguage(name string) (string, LanguageInfo, bool) {
+	if name == "" {
+		return "", LanguageInfo{}, false
+	}
+	if lang, info, found := LookupByExtension("." + strings.ToLower(name)); found {
+		return lang, info, true
+	}
+	for lang, info := range Languages {
+		if strings.EqualFold(lang, name) {
+			return lang, info, true
 		}
 	}
 	return "", LanguageInfo{}, false
@@ -268,6 +537,115 @@ func LookupByFileName(fileName string) (string, LanguageInfo, bool) {
 	return "", LanguageInfo{}, false
 }
 
+// LookupByInterpreter finds the language of an interpreter named in a shebang, ex: "python3".
+// Version numbers are ignored if the exact interpreter is not configured, ex: "python3.11" finds "python3" and then "python".
+func LookupByInterpreter(interpreter string) (string, LanguageInfo, bool) {
+	for interpreter != "" {
+		for lang, info := range Languages {
+			for _, languageInterpreter := range info.Interpreters {
+				if languageInterpreter == interpreter {
+					return lang, info, true
+				}
+			}
+		}
+		// drop the last part of the version number and try again
+		trimmed := strings.TrimRight(interpreter, "0123456789")
+		trimmed = strings.TrimSuffix(trimmed, ".")
+		if trimmed == interpreter {
+			break
+		}
+		interpreter = trimmed
+	}
+	return "", LanguageInfo{}, false
+}
+
+// LookupByShebang finds the language of a script by the interpreter named in its first line, ex: #!/usr/bin/env python3
+func LookupByShebang(filePath string) (string, LanguageInfo, bool) {
+	// most files without a suffix are not scripts, only the first bytes are read to rule them out
+	if !startsWithShebang(filePath) {
+		return "", LanguageInfo{}, false
+	}
+	interpreter := ParseShebangInterpreter(readFirstLine(filePath))
+	if interpreter == "" {
+		return "", LanguageInfo{}, false
+	}
+	return LookupByInterpreter(interpreter)
+}
+
+// ParseShebangInterpreter returns the name of the interpreter in a shebang line, empty if the line is not a shebang
+//
+// Examples:
+//   - "#!/bin/bash" returns "bash"
+//   - "#!/usr/bin/env -S python3 -u" returns "python3"
+func ParseShebangInterpreter(line string) string {
+	if !strings.HasPrefix(line, "#!") {
+		return ""
+	}
+	fields := strings.Fields(line[2:])
+	if len(fields) == 0 {
+		return ""
+	}
+	interpreter := path.Base(fields[0])
+	if interpreter != "env" {
+		return interpreter
+	}
+	// env runs the first argument which is not an option or an environment variable
+	for _, field := range fields[1:] {
+		if strings.HasPrefix(field, "-") || strings.Contains(field, "=") {
+			continue
+		}
+		return path.Base(field)
+	}
+	return ""
+}
+
+// returns true if the raw content of the file starts with #!, false if the file cannot be read
+func startsWithShebang(filePath string) bool {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	start := make([]byte, 2)
+	n, _ := io.ReadFull(f, start)
+	return n == 2 && string(start) == "#!"
+}
+
+// IsInDotDirectory returns true if a directory of the path relative to the scanned directory starts with a dot,
+// ex: .git/objects/3f/2a9c. Such directories hold the internals of tools, whose files without a suffix are not scripts.
+func IsInDotDirectory(relativePath string) bool {
+	for _, directory := range strings.Split(filepath.ToSlash(filepath.Dir(relativePath)), "/") {
+		if len(directory) > 1 && directory[0] == '.' && directory != ".." {
+			return true
+		}
+	}
+	return false
+}
+
+// shebangs longer than this are not read
+const maxShebangLength = 256
+
+// reads up to the first line of a file, empty if the file cannot be read
+func readFirstLine(filePath string) string {
+	line, _, _ := strings.Cut(string(readFileHead(filePath, maxShebangLength)), "\n")
+	return strings.TrimSpace(line)
+}
+
+// reads up to maxLength bytes from the start of a file decoded to UTF-8, empty if the file cannot be read
+func readFileHead(filePath string, maxLength int) []byte {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return []byte{}
+	}
+	defer f.Close()
+
+	reader, _ := DecodeText(bufio.NewReaderSize(f, binarySniffLength), Options.FallbackEncoding)
+	buf := make([]byte, maxLength)
+	n, _ := io.ReadFull(reader, buf)
+	return buf[:n]
+}
+
 func PrintLanguages() {
 	logger.Info("Supported Languages:")
 	// Create a buffer to hold the JSON data
@@ -287,7 +665,7 @@ func PrintLanguages() {
 	fmt.Println(buf.String())
 }
 
-// LoadLanguages reads the JSON file and overrides the default Languages map
+// LoadLanguages reads the JSON file and overrides the default Languages map, the problems found in it are logged as warnings
 func LoadLanguages(fileName string) {
 	file, err := os.Open(fileName)
 	if err != nil {
@@ -300,8 +678,22 @@ func LoadLanguages(fileName string) {
 		logger.LogStackTraceAndExit(err)
 	}
 
+	// mistakes that would be silently ignored are reported, ex: a misspelled key, see ValidateLanguages
+	for _, problem := range ValidateLanguages(byteValue, Languages) {
+		logger.Warn(fileName + ":" + problem.String())
+	}
+
 	err = json.Unmarshal(byteValue, &Languages)
 	if err != nil {
 		logger.LogStackTraceAndExit(err)
 	}
+
+	// the line patterns are compiled once, instead of for every file scanned
+	for langName, languageInfo := range Languages {
+		if pattern, err := languageInfo.compilePatterns(); err != nil {
+			logger.Error("Invalid pattern ", pattern, " of language ", langName, ": ", err)
+			os.Exit(-1)
+		}
+		Languages[langName] = languageInfo
+	}
 }
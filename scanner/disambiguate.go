@@ -0,0 +1,66 @@
+package scanner
+
+import (
+	"regexp"
+	"slices"
+)
+
+// number of bytes read from the start of a file to run the heuristics on
+const heuristicsReadLength = 64 * 1024
+
+// a content heuristic, the language is chosen if the pattern matches the start of the file
+type heuristic struct {
+	langName string
+	pattern  *regexp.Regexp
+}
+
+// heuristics for extensions claimed by several languages, checked in order.
+// If none match, the language with the highest priority claiming the extension is used.
+var extensionHeuristics = map[string][]heuristic{
+	".as": {
+		{"Flex", regexp.MustCompile(`(?m)^\s*import\s+(mx|spark)\.`)},
+		{"ActionScript", regexp.MustCompile(`(?m)^\s*import\s+flash\.`)},
+	},
+	".h": {
+		{"Objective-C", regexp.MustCompile(`(?m)^\s*(@interface|@implementation|@protocol|@property|@end\b|#import\b)`)},
+		{"C++ Header", regexp.MustCompile(`(?m)^\s*(class\s+\w+[^;]*$|namespace\s+\w+|template\s*<|(public|private|protected)\s*:)|std::|#include\s*<(iostream|string|vector|map|memory)>`)},
+	},
+	".m": {
+		{"Objective-C", regexp.MustCompile(`(?m)^\s*(@interface|@implementation|@protocol|@property|@end\b|#import\b|#include\b)`)},
+		{"MATLAB", regexp.MustCompile(`(?m)^\s*(function\s|end\s*$|%)`)},
+	},
+	".inc": {
+		{"PHP", regexp.MustCompile(`<\?php|(?m)^\s*\$\w+\s*=`)},
+		{"Pascal", regexp.MustCompile(`(?im)^\s*(procedure|function|begin|end\s*[;.]|unit|uses|interface|implementation)\b`)},
+	},
+	".cls": {
+		{"LaTeX", regexp.MustCompile(`\\(NeedsTeXFormat|ProvidesClass|LoadClass|documentclass|newcommand|renewcommand|def)\b`)},
+		{"Apex", regexp.MustCompile(`(?i)\b(public|private|global)\s+(virtual\s+|abstract\s+|with\s+sharing\s+|without\s+sharing\s+)*(class|interface|enum)\b`)},
+	},
+}
+
+// LookupByExtensionAndContent finds the language of a file by its extension. When several languages claim the
+// extension, ex: .h for C, C++ and Objective-C, content heuristics are run on the start of the file to choose one.
+// If none of the heuristics match, the language with the highest priority is used, see LookupByExtension.
+func LookupByExtensionAndContent(ext string, filePath string) (string, LanguageInfo, bool) {
+	candidates := languagesClaimingExtension(ext)
+	if len(candidates) == 0 {
+		return "", LanguageInfo{}, false
+	}
+	if len(candidates) > 1 {
+		if langName, found := matchHeuristics(ext, candidates, readFileHead(filePath, heuristicsReadLength)); found {
+			return langName, Languages[langName], true
+		}
+	}
+	return candidates[0], Languages[candidates[0]], true
+}
+
+// returns the first language claiming the extension whose heuristic matches the content
+func matchHeuristics(ext string, candidates []string, content []byte) (string, bool) {
+	for _, h := range extensionHeuristics[ext] {
+		if slices.Contains(candidates, h.langName) && h.pattern.Match(content) {
+			return h.langName, true
+		}
+	}
+	return "", false
+}
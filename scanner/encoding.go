@@ -0,0 +1,255 @@
+package scanner
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Encodings that files are decoded from before they are scanned
+const (
+	EncodingUTF8        = "utf-8"
+	EncodingUTF16LE     = "utf-16le"
+	EncodingUTF16BE     = "utf-16be"
+	EncodingUTF32LE     = "utf-32le"
+	EncodingUTF32BE     = "utf-32be"
+	EncodingWindows1252 = "windows-1252"
+	EncodingLatin1      = "iso-8859-1"
+	EncodingEBCDIC      = "ebcdic-037"
+)
+
+// byte order marks, the UTF-32 marks are checked first since the UTF-16LE mark is a prefix of the UTF-32LE mark
+var byteOrderMarks = []struct {
+	bom      []byte
+	encoding string
+}{
+	{[]byte{0x00, 0x00, 0xFE, 0xFF}, EncodingUTF32BE},
+	{[]byte{0xFF, 0xFE, 0x00, 0x00}, EncodingUTF32LE},
+	{[]byte{0xEF, 0xBB, 0xBF}, EncodingUTF8},
+	{[]byte{0xFE, 0xFF}, EncodingUTF16BE},
+	{[]byte{0xFF, 0xFE}, EncodingUTF16LE},
+}
+
+// encodings that can be used as Options.FallbackEncoding, keyed by every accepted name
+var fallbackEncodingNames = map[string]string{
+	"utf-8":        EncodingUTF8,
+	"utf8":         EncodingUTF8,
+	"windows-1252": EncodingWindows1252,
+	"cp1252":       EncodingWindows1252,
+	"iso-8859-1":   EncodingLatin1,
+	"latin-1":      EncodingLatin1,
+	"latin1":       EncodingLatin1,
+	"ebcdic-037":   EncodingEBCDIC,
+	"ebcdic":       EncodingEBCDIC,
+	"cp037":        EncodingEBCDIC,
+	"ibm037":       EncodingEBCDIC,
+}
+
+// LookupFallbackEncoding returns the canonical name of an encoding supported as a fallback encoding, ex: cp1252 is windows-1252
+func LookupFallbackEncoding(name string) (string, bool) {
+	encoding, found := fallbackEncodingNames[strings.ToLower(strings.TrimSpace(name))]
+	return encoding, found
+}
+
+// DecodeText returns a reader of the file's content as UTF-8 along with the encoding it was decoded from.
+// Files starting with a byte order mark are decoded from UTF-8, UTF-16 or UTF-32 and the mark is dropped.
+// Files without a byte order mark whose start is not valid UTF-8 are decoded from the fallback encoding,
+// ex: windows-1252, otherwise they are read as is.
+func DecodeText(r *bufio.Reader, fallbackEncoding string) (*bufio.Reader, string) {
+	head, _ := r.Peek(4)
+	for _, byteOrderMark := range byteOrderMarks {
+		if !bytes.HasPrefix(head, byteOrderMark.bom) {
+			continue
+		}
+		r.Discard(len(byteOrderMark.bom))
+		if byteOrderMark.encoding == EncodingUTF8 {
+			return r, EncodingUTF8
+		}
+		return newDecodingReader(r, byteOrderMark.encoding), byteOrderMark.encoding
+	}
+
+	encoding, found := LookupFallbackEncoding(fallbackEncoding)
+	if !found || encoding == EncodingUTF8 {
+		return r, EncodingUTF8
+	}
+	head, _ = r.Peek(binarySniffLength)
+	if isValidUTF8Prefix(head) {
+		return r, EncodingUTF8
+	}
+	return newDecodingReader(r, encoding), encoding
+}
+
+// returns true if the block is valid UTF-8, ignoring a multi-byte character cut off at the end of the block
+func isValidUTF8Prefix(head []byte) bool {
+	for i := 0; i < len(head); {
+		r, size := utf8.DecodeRune(head[i:])
+		if r == utf8.RuneError && size == 1 {
+			return !utf8.FullRune(head[i:])
+		}
+		i += size
+	}
+	return true
+}
+
+func newDecodingReader(r *bufio.Reader, encoding string) *bufio.Reader {
+	var decode func(r *bufio.Reader) (rune, error)
+	switch encoding {
+	case EncodingUTF16LE:
+		decode = func(r *bufio.Reader) (rune, error) { return decodeUTF16(r, binary.LittleEndian) }
+	case EncodingUTF16BE:
+		decode = func(r *bufio.Reader) (rune, error) { return decodeUTF16(r, binary.BigEndian) }
+	case EncodingUTF32LE:
+		decode = func(r *bufio.Reader) (rune, error) { return decodeUTF32(r, binary.LittleEndian) }
+	case EncodingUTF32BE:
+		decode = func(r *bufio.Reader) (rune, error) { return decodeUTF32(r, binary.BigEndian) }
+	case EncodingWindows1252:
+		decode = decodeWindows1252
+	case EncodingLatin1:
+		decode = decodeLatin1
+	case EncodingEBCDIC:
+		decode = decodeEBCDIC
+	}
+	return bufio.NewReaderSize(&decodingReader{source: r, decode: decode}, binarySniffLength)
+}
+
+// decodingReader converts text in another encoding to UTF-8 one character at a time
+type decodingReader struct {
+	source  *bufio.Reader
+	decode  func(r *bufio.Reader) (rune, error)
+	pending []byte // the rest of a character that did not fit in the previous read
+}
+
+func (d *decodingReader) Read(p []byte) (int, error) {
+	n := copy(p, d.pending)
+	d.pending = d.pending[n:]
+	for n < len(p) {
+		r, err := d.decode(d.source)
+		if err != nil {
+			if n > 0 {
+				return n, nil
+			}
+			return 0, err
+		}
+		var encoded [utf8.UTFMax]byte
+		size := utf8.EncodeRune(encoded[:], r)
+		copied := copy(p[n:], encoded[:size])
+		d.pending = append(d.pending, encoded[copied:size]...)
+		n += copied
+	}
+	return n, nil
+}
+
+// reads one character of UTF-16, unpaired surrogates are replaced with U+FFFD
+func decodeUTF16(r *bufio.Reader, order binary.ByteOrder) (rune, error) {
+	unit, err := readCodeUnit(r, 2)
+	if err != nil {
+		return 0, err
+	}
+	first := rune(order.Uint16(unit))
+	r.Discard(2)
+	if !utf16.IsSurrogate(first) {
+		return first, nil
+	}
+	// a high surrogate is followed by the low surrogate that completes the character
+	unit, err = readCodeUnit(r, 2)
+	if err != nil {
+		return utf8.RuneError, nil
+	}
+	decoded := utf16.DecodeRune(first, rune(order.Uint16(unit)))
+	if decoded != utf8.RuneError {
+		r.Discard(2)
+	}
+	return decoded, nil
+}
+
+// reads one character of UTF-32, invalid code points are replaced with U+FFFD
+func decodeUTF32(r *bufio.Reader, order binary.ByteOrder) (rune, error) {
+	unit, err := readCodeUnit(r, 4)
+	if err != nil {
+		return 0, err
+	}
+	decoded := rune(order.Uint32(unit))
+	r.Discard(4)
+	if !utf8.ValidRune(decoded) {
+		return utf8.RuneError, nil
+	}
+	return decoded, nil
+}
+
+// peeks at the next code unit, an incomplete code unit at the end of the file is ignored
+func readCodeUnit(r *bufio.Reader, size int) ([]byte, error) {
+	unit, err := r.Peek(size)
+	if len(unit) < size {
+		if err == nil || err == io.ErrUnexpectedEOF {
+			err = io.EOF
+		}
+		return nil, err
+	}
+	return unit, nil
+}
+
+func decodeLatin1(r *bufio.Reader) (rune, error) {
+	b, err := r.ReadByte()
+	return rune(b), err
+}
+
+func decodeWindows1252(r *bufio.Reader) (rune, error) {
+	b, err := r.ReadByte()
+	if b >= 0x80 && b < 0xA0 {
+		return windows1252HighControls[b-0x80], err
+	}
+	return rune(b), err
+}
+
+func decodeEBCDIC(r *bufio.Reader) (rune, error) {
+	b, err := r.ReadByte()
+	return ebcdic037[b], err
+}
+
+// Windows-1252 is ISO-8859-1 except for the bytes 0x80 to 0x9F, undefined bytes keep their ISO-8859-1 meaning
+var windows1252HighControls = [32]rune{
+	0x20AC, 0x0081, 0x201A, 0x0192, 0x201E, 0x2026, 0x2020, 0x2021,
+	0x02C6, 0x2030, 0x0160, 0x2039, 0x0152, 0x008D, 0x017D, 0x008F,
+	0x0090, 0x2018, 0x2019, 0x201C, 0x201D, 0x2022, 0x2013, 0x2014,
+	0x02DC, 0x2122, 0x0161, 0x203A, 0x0153, 0x009D, 0x017E, 0x0178,
+}
+
+// EBCDIC code page 037, used by IBM mainframes. The new line character 0x15 is decoded as a line feed.
+var ebcdic037 = [256]rune{
+	0x0000, 0x0001, 0x0002, 0x0003, 0x009C, 0x0009, 0x0086, 0x007F,
+	0x0097, 0x008D, 0x008E, 0x000B, 0x000C, 0x000D, 0x000E, 0x000F,
+	0x0010, 0x0011, 0x0012, 0x0013, 0x009D, 0x000A, 0x0008, 0x0087,
+	0x0018, 0x0019, 0x0092, 0x008F, 0x001C, 0x001D, 0x001E, 0x001F,
+	0x0080, 0x0081, 0x0082, 0x0083, 0x0084, 0x000A, 0x0017, 0x001B,
+	0x0088, 0x0089, 0x008A, 0x008B, 0x008C, 0x0005, 0x0006, 0x0007,
+	0x0090, 0x0091, 0x0016, 0x0093, 0x0094, 0x0095, 0x0096, 0x0004,
+	0x0098, 0x0099, 0x009A, 0x009B, 0x0014, 0x0015, 0x009E, 0x001A,
+	0x0020, 0x00A0, 0x00E2, 0x00E4, 0x00E0, 0x00E1, 0x00E3, 0x00E5,
+	0x00E7, 0x00F1, 0x00A2, 0x002E, 0x003C, 0x0028, 0x002B, 0x007C,
+	0x0026, 0x00E9, 0x00EA, 0x00EB, 0x00E8, 0x00ED, 0x00EE, 0x00EF,
+	0x00EC, 0x00DF, 0x0021, 0x0024, 0x002A, 0x0029, 0x003B, 0x00AC,
+	0x002D, 0x002F, 0x00C2, 0x00C4, 0x00C0, 0x00C1, 0x00C3, 0x00C5,
+	0x00C7, 0x00D1, 0x00A6, 0x002C, 0x0025, 0x005F, 0x003E, 0x003F,
+	0x00F8, 0x00C9, 0x00CA, 0x00CB, 0x00C8, 0x00CD, 0x00CE, 0x00CF,
+	0x00CC, 0x0060, 0x003A, 0x0023, 0x0040, 0x0027, 0x003D, 0x0022,
+	0x00D8, 0x0061, 0x0062, 0x0063, 0x0064, 0x0065, 0x0066, 0x0067,
+	0x0068, 0x0069, 0x00AB, 0x00BB, 0x00F0, 0x00FD, 0x00FE, 0x00B1,
+	0x00B0, 0x006A, 0x006B, 0x006C, 0x006D, 0x006E, 0x006F, 0x0070,
+	0x0071, 0x0072, 0x00AA, 0x00BA, 0x00E6, 0x00B8, 0x00C6, 0x00A4,
+	0x00B5, 0x007E, 0x0073, 0x0074, 0x0075, 0x0076, 0x0077, 0x0078,
+	0x0079, 0x007A, 0x00A1, 0x00BF, 0x00D0, 0x00DD, 0x00DE, 0x00AE,
+	0x005E, 0x00A3, 0x00A5, 0x00B7, 0x00A9, 0x00A7, 0x00B6, 0x00BC,
+	0x00BD, 0x00BE, 0x005B, 0x005D, 0x00AF, 0x00A8, 0x00B4, 0x00D7,
+	0x007B, 0x0041, 0x0042, 0x0043, 0x0044, 0x0045, 0x0046, 0x0047,
+	0x0048, 0x0049, 0x00AD, 0x00F4, 0x00F6, 0x00F2, 0x00F3, 0x00F5,
+	0x007D, 0x004A, 0x004B, 0x004C, 0x004D, 0x004E, 0x004F, 0x0050,
+	0x0051, 0x0052, 0x00B9, 0x00FB, 0x00FC, 0x00F9, 0x00FA, 0x00FF,
+	0x005C, 0x00F7, 0x0053, 0x0054, 0x0055, 0x0056, 0x0057, 0x0058,
+	0x0059, 0x005A, 0x00B2, 0x00D4, 0x00D6, 0x00D2, 0x00D3, 0x00D5,
+	0x0030, 0x0031, 0x0032, 0x0033, 0x0034, 0x0035, 0x0036, 0x0037,
+	0x0038, 0x0039, 0x00B3, 0x00DB, 0x00DC, 0x00D9, 0x00DA, 0x009F,
+}
@@ -0,0 +1,105 @@
+package scanner
+
+import (
+	"bytes"
+	"go-cloc/logger"
+	"io"
+	"regexp"
+	"unicode"
+)
+
+// JCL is the name of the language entry for z/OS job control language, whose statements are recognized by the start
+// of each line rather than by comment tokens, ex: //* is a comment but // starts a statement
+const JCL = "JCL"
+
+// a DD statement followed by in-stream data, ex: //SYSIN DD *,DLM=@@
+var jclInStreamDataRegex = regexp.MustCompile(`(?i)^//\S*\s+DD\s+(\*|DATA)(,|\s|$)`)
+
+// the delimiter of the in-stream data if it is not the default, ex: DLM=@@ or DLM='$$'
+var jclDelimiterRegex = regexp.MustCompile(`(?i)\bDLM=(?:'([^']+)'|([^\s,']+))`)
+
+// ends in-stream data unless the DD statement names another delimiter
+const jclDefaultDelimiter = "/*"
+
+// ScanJCL counts the lines of a JCL member. Lines starting with //* are comments and every other statement is code,
+// including delimiters and JES2 control statements starting with /*, ex: /*JOBPARM. The in-stream data following
+// DD * or DD DATA is counted as code up to its delimiter, even if it looks like a comment. The data of DD * also ends
+// at the next statement unless it has a DLM delimiter. Columns outside of the statement, ex: the sequence numbers
+// in columns 73 to 80, are not counted.
+func ScanJCL(r io.Reader, filePath string) FileScanResults {
+	languageInfo := Languages[JCL]
+	result := FileScanResults{
+		FilePath:     filePath,
+		LanguageName: JCL,
+	}
+
+	reader := newLineReader(r)
+	defer reader.release()
+	// the delimiter ending the in-stream data the line is part of, empty outside of in-stream data
+	delimiter := ""
+	endsAtStatement := false
+	var columns []byte
+	for {
+		chunk, endOfLine, err := reader.readChunk(lineChunkSize)
+		// statements are recognized by the start of the line, the rest of a huge line is skipped
+		columns, _ = applyColumnRules(columns[:0], chunk, languageInfo)
+		for !endOfLine && err == nil {
+			_, endOfLine, err = reader.readChunk(lineChunkSize)
+		}
+		line := bytes.TrimRightFunc(columns, unicode.IsSpace)
+
+		lineResult := Code
+		switch {
+		case delimiter != "" && hasPrefix(line, delimiter):
+			delimiter = ""
+		case delimiter != "" && !(endsAtStatement && hasPrefix(line, "//")):
+			// in-stream data
+			if len(bytes.TrimSpace(line)) == 0 {
+				lineResult = BlankLine
+			}
+		default:
+			delimiter = ""
+			lineResult = classifyJCLStatement(line, languageInfo)
+			if match := jclInStreamDataRegex.FindSubmatch(line); match != nil && lineResult == Code {
+				delimiter, endsAtStatement = jclDefaultDelimiter, string(match[1]) == "*"
+				if dlm := jclDelimiterRegex.FindSubmatch(line); dlm != nil {
+					delimiter, endsAtStatement = string(dlm[1])+string(dlm[2]), false
+				}
+			}
+		}
+
+		if lineResult == Code {
+			result.CodeLineCount++
+		} else if lineResult == BlankLine {
+			result.BlankLineCount++
+		} else if lineResult == Comment {
+			result.CommentsLineCount++
+		}
+
+		if err != nil {
+			// reached end of file
+			if err == io.EOF {
+				break
+			}
+			logger.LogStackTraceAndExit(err)
+		}
+	}
+
+	result.TotalLines = result.CodeLineCount + result.CommentsLineCount + result.BlankLineCount
+	result.LineEnding = reader.lineEnding()
+	return result
+}
+
+// classifies a line outside of in-stream data, a comment starts in column 1, ex: //* COMMENT
+func classifyJCLStatement(line []byte, languageInfo LanguageInfo) AnalyzeLineResult {
+	if len(bytes.TrimSpace(line)) == 0 {
+		return BlankLine
+	}
+	for _, lineComment := range languageInfo.LineComments {
+		if lineComment != "" && hasPrefix(line, lineComment) {
+			return Comment
+		}
+	}
+	// statements and data without a DD statement, which the system reads as in-stream data
+	return Code
+}
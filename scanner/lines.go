@@ -0,0 +1,139 @@
+package scanner
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"sync"
+)
+
+// lines are read and classified in chunks of this many bytes, which bounds the memory used by huge lines
+const lineChunkSize = 64 * 1024
+
+// Line endings of a file, a file without any line break has no line ending
+const (
+	LineEndingLF    = "LF"
+	LineEndingCRLF  = "CRLF"
+	LineEndingCR    = "CR"
+	LineEndingMixed = "mixed"
+)
+
+// lineReader splits text into lines ending in \n, \r\n or a lone \r, ex: classic Mac OS files, and counts the line
+// endings it finds so the line ending style of the file can be reported
+type lineReader struct {
+	reader    *bufio.Reader
+	chunk     []byte // holds the chunk returned by the last read when it could not be returned from the read buffer
+	lfCount   int
+	crlfCount int
+	crCount   int
+}
+
+// line readers are reused across files, which avoids allocating a read buffer for every file scanned
+var lineReaderPool = sync.Pool{
+	New: func() any {
+		return &lineReader{reader: bufio.NewReaderSize(nil, lineChunkSize)}
+	},
+}
+
+// newLineReader returns a line reader reading from r in large reads, release returns it to the pool once it is done
+func newLineReader(r io.Reader) *lineReader {
+	l := lineReaderPool.Get().(*lineReader)
+	l.reader.Reset(r)
+	l.lfCount, l.crlfCount, l.crCount = 0, 0, 0
+	return l
+}
+
+// release returns the line reader to the pool, it must not be used afterwards
+func (l *lineReader) release() {
+	l.reader.Reset(nil)
+	// a chunk grown to hold a huge line of a component is not kept
+	if cap(l.chunk) > lineChunkSize {
+		l.chunk = nil
+	}
+	lineReaderPool.Put(l)
+}
+
+// readChunk returns up to maxLength bytes of the current line and true if the line ends after them. Like
+// bufio.Reader.ReadString, the text after the last line break is returned along with io.EOF, even when it is empty.
+// The chunk is only valid until the next read, a line that is already buffered is returned without being copied.
+func (l *lineReader) readChunk(maxLength int) ([]byte, bool, error) {
+	l.chunk = l.chunk[:0]
+	for len(l.chunk) < maxLength {
+		// fill the buffer if it is empty and search what is buffered for the end of the line
+		if _, err := l.reader.Peek(1); err != nil {
+			return l.chunk, true, err
+		}
+		buffered, _ := l.reader.Peek(min(l.reader.Buffered(), maxLength-len(l.chunk)))
+		end := indexLineBreak(buffered)
+		if end == -1 {
+			l.chunk = append(l.chunk, buffered...)
+			l.reader.Discard(len(buffered))
+			continue
+		}
+
+		line := buffered[:end]
+		lineBreak := buffered[end]
+		l.reader.Discard(end + 1)
+		// the line is copied if it spans several reads or if looking for the \n of a \r\n refills the buffer
+		if len(l.chunk) > 0 || (lineBreak == '\r' && l.reader.Buffered() == 0) {
+			l.chunk = append(l.chunk, line...)
+			line = l.chunk
+		}
+		if lineBreak == '\n' {
+			l.lfCount++
+		} else if next, err := l.reader.Peek(1); err == nil && next[0] == '\n' {
+			l.reader.Discard(1)
+			l.crlfCount++
+		} else {
+			l.crCount++
+		}
+		return line, true, nil
+	}
+	return l.chunk, false, nil
+}
+
+// returns the index of the first \n or \r of the text, -1 if there is none. The \n ending most lines is searched
+// for first, then the text before it is searched for a \r.
+func indexLineBreak(text []byte) int {
+	end := bytes.IndexByte(text, '\n')
+	if end == -1 {
+		end = len(text)
+	}
+	if cr := bytes.IndexByte(text[:end], '\r'); cr != -1 {
+		return cr
+	}
+	if end == len(text) {
+		return -1
+	}
+	return end
+}
+
+// readLine returns the next line without its line ending, truncated to maxLength bytes so that a huge line
+// does not have to be held in memory. The text after the last line break is returned along with io.EOF.
+func (l *lineReader) readLine(maxLength int) (string, error) {
+	chunk, endOfLine, err := l.readChunk(maxLength)
+	line := string(chunk)
+	// skip the rest of a line that is too long
+	for !endOfLine {
+		_, endOfLine, err = l.readChunk(lineChunkSize)
+	}
+	return line, err
+}
+
+// lineEnding returns the line ending used by every line read so far, mixed if there was more than one
+func (l *lineReader) lineEnding() string {
+	lineEnding := ""
+	for _, count := range []struct {
+		lineEnding string
+		count      int
+	}{{LineEndingLF, l.lfCount}, {LineEndingCRLF, l.crlfCount}, {LineEndingCR, l.crCount}} {
+		if count.count == 0 {
+			continue
+		}
+		if lineEnding != "" {
+			return LineEndingMixed
+		}
+		lineEnding = count.lineEnding
+	}
+	return lineEnding
+}
@@ -0,0 +1,124 @@
+package scanner
+
+import (
+	"encoding/json"
+	"go-cloc/logger"
+	"io"
+	"strings"
+)
+
+// JupyterNotebook is the name of the language entry for notebooks, whose cells are scanned instead of the raw JSON
+const JupyterNotebook = "Jupyter Notebook"
+
+type notebook struct {
+	Cells    []notebookCell   `json:"cells"`
+	Metadata notebookMetadata `json:"metadata"`
+}
+
+type notebookCell struct {
+	CellType string          `json:"cell_type"`
+	Source   json.RawMessage `json:"source"` // either a string or a list of lines
+}
+
+type notebookMetadata struct {
+	KernelSpec struct {
+		Language string `json:"language"`
+	} `json:"kernelspec"`
+	LanguageInfo struct {
+		Name          string `json:"name"`
+		FileExtension string `json:"file_extension"`
+	} `json:"language_info"`
+}
+
+// ScanNotebook counts the lines of a Jupyter notebook. Only code cells are counted as code, using the comment
+// rules of the kernel's language. Markdown cells are counted as comments if Options.NotebookMarkdownAsComments
+// is set, otherwise they are ignored along with outputs and metadata.
+func ScanNotebook(r io.Reader, filePath string) FileScanResults {
+	result := FileScanResults{
+		FilePath:     filePath,
+		LanguageName: JupyterNotebook,
+	}
+
+	// the notebook is parsed as a whole, so it is skipped if it does not fit in the memory limit
+	byteValue, err := io.ReadAll(io.LimitReader(r, int64(Options.FileMemoryLimit)+1))
+	if err != nil {
+		logger.Error("File ", filePath, " failed to scan. Counting as 0")
+		logger.Error(err)
+		result.SkipReason = SkipReasonUnreadable + ": " + err.Error()
+		return result
+	}
+	if len(byteValue) > Options.FileMemoryLimit {
+		logger.Warn("File ", filePath, " is larger than the memory limit of ", Options.FileMemoryLimit, " bytes. Skipping")
+		result.SkipReason = SkipReasonTooLarge
+		return result
+	}
+	var parsedNotebook notebook
+	err = json.Unmarshal(byteValue, &parsedNotebook)
+	if err != nil {
+		logger.Error("File ", filePath, " is not a valid Jupyter notebook. Skipping")
+		logger.Error(err)
+		result.SkipReason = SkipReasonInvalidNotebook
+		return result
+	}
+
+	langName, languageInfo, found := lookupNotebookLanguage(parsedNotebook.Metadata)
+	if found {
+		result.LanguageName = langName
+	} else {
+		logger.Debug("Kernel language of ", filePath, " is not supported, counting code cells without comment rules")
+	}
+
+	for _, cell := range parsedNotebook.Cells {
+		source := parseCellSource(cell.Source)
+		if source == "" {
+			continue
+		}
+		switch cell.CellType {
+		case "code":
+			codeLineCount, commentsLineCount, blankLineCount, _ := scanLines(strings.NewReader(source), languageInfo)
+			result.CodeLineCount += codeLineCount
+			result.CommentsLineCount += commentsLineCount
+			result.BlankLineCount += blankLineCount
+		case "markdown":
+			if Options.NotebookMarkdownAsComments {
+				for _, line := range strings.Split(strings.TrimSuffix(source, "\n"), "\n") {
+					if strings.TrimSpace(line) == "" {
+						result.BlankLineCount++
+					} else {
+						result.CommentsLineCount++
+					}
+				}
+			}
+		}
+	}
+	result.TotalLines = result.CodeLineCount + result.CommentsLineCount + result.BlankLineCount
+	return result
+}
+
+// returns the source of a cell as a single string, the source is stored either as a string or a list of lines
+func parseCellSource(source json.RawMessage) string {
+	var lines []string
+	if err := json.Unmarshal(source, &lines); err == nil {
+		return strings.Join(lines, "")
+	}
+	var text string
+	if err := json.Unmarshal(source, &text); err == nil {
+		return text
+	}
+	return ""
+}
+
+// finds the language of the notebook's kernel, first by the file extension it declares and then by name
+func lookupNotebookLanguage(metadata notebookMetadata) (string, LanguageInfo, bool) {
+	if extension := strings.ToLower(metadata.LanguageInfo.FileExtension); extension != "" {
+		if langName, languageInfo, found := LookupByExtension(extension); found && langName != JupyterNotebook {
+			return langName, languageInfo, true
+		}
+	}
+	for _, kernelLanguage := range []string{metadata.LanguageInfo.Name, metadata.KernelSpec.Language} {
+		if langName, languageInfo, found := lookupLanguage(kernelLanguage); found && langName != JupyterNotebook {
+			return langName, languageInfo, true
+		}
+	}
+	return "", LanguageInfo{}, false
+}
@@ -0,0 +1,61 @@
+package scanner
+
+import "regexp"
+
+// Ignored is the result of a line matching one of the IgnorePatterns of its language, it is not counted at all
+const Ignored AnalyzeLineResult = "ignored"
+
+// linePatterns are the compiled regular expressions classifying whole lines of a language, ex: Fortran's ^[cC*]
+type linePatterns struct {
+	ignore       []*regexp.Regexp
+	blank        []*regexp.Regexp
+	lineComments []*regexp.Regexp
+}
+
+// compilePatterns compiles the regular expressions of the language once, so they are not compiled for every file.
+// Returns the first pattern that is not a valid regular expression along with its error.
+func (languageInfo *LanguageInfo) compilePatterns() (string, error) {
+	languageInfo.patterns = nil
+	if len(languageInfo.IgnorePatterns) == 0 && len(languageInfo.BlankPatterns) == 0 && len(languageInfo.LineCommentPatterns) == 0 {
+		return "", nil
+	}
+	patterns := &linePatterns{}
+	for _, group := range []struct {
+		patterns []string
+		regexps  *[]*regexp.Regexp
+	}{
+		{languageInfo.IgnorePatterns, &patterns.ignore},
+		{languageInfo.BlankPatterns, &patterns.blank},
+		{languageInfo.LineCommentPatterns, &patterns.lineComments},
+	} {
+		for _, pattern := range group.patterns {
+			compiled, err := regexp.Compile(pattern)
+			if err != nil {
+				return pattern, err
+			}
+			*group.regexps = append(*group.regexps, compiled)
+		}
+	}
+	languageInfo.patterns = patterns
+	return "", nil
+}
+
+// match returns how the whole line is classified by the patterns, an empty result if no pattern matches it.
+// Ignore patterns are checked first, then blank patterns and then line comment patterns.
+func (patterns *linePatterns) match(line []byte) AnalyzeLineResult {
+	for _, group := range []struct {
+		regexps []*regexp.Regexp
+		result  AnalyzeLineResult
+	}{
+		{patterns.ignore, Ignored},
+		{patterns.blank, BlankLine},
+		{patterns.lineComments, Comment},
+	} {
+		for _, pattern := range group.regexps {
+			if pattern.Match(line) {
+				return group.result
+			}
+		}
+	}
+	return ""
+}
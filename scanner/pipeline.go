@@ -0,0 +1,78 @@
+package scanner
+
+import (
+	"runtime"
+	"sync"
+)
+
+type scanJob struct {
+	index int
+	file  FileToScan
+}
+
+type scanJobResult struct {
+	index  int
+	result FileScanResults
+}
+
+// DefaultWorkerCount returns the number of workers used when none is specified, one per usable CPU
+func DefaultWorkerCount() int {
+	return runtime.GOMAXPROCS(0)
+}
+
+// ScanFiles scans every file received on the channel using a bounded pool of workers.
+// Results are returned in the order the files were received, so the output is identical
+// to calling ScanFile sequentially regardless of how the workers are scheduled.
+func ScanFiles(files <-chan FileToScan, workers int) []FileScanResults {
+	if workers < 1 {
+		workers = 1
+	}
+
+	jobs := make(chan scanJob, workers)
+	jobResults := make(chan scanJobResult, workers)
+
+	// start the workers
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				jobResults <- scanJobResult{index: job.index, result: scanFoundFile(job.file)}
+			}
+		}()
+	}
+
+	// feed the workers, numbering each file so results can be put back in order
+	go func() {
+		index := 0
+		for file := range files {
+			jobs <- scanJob{index: index, file: file}
+			index++
+		}
+		close(jobs)
+		wg.Wait()
+		close(jobResults)
+	}()
+
+	// collect the results in the order the files were received
+	fileScanResultsArr := []FileScanResults{}
+	for jobResult := range jobResults {
+		for len(fileScanResultsArr) <= jobResult.index {
+			fileScanResultsArr = append(fileScanResultsArr, FileScanResults{})
+		}
+		fileScanResultsArr[jobResult.index] = jobResult.result
+	}
+	return fileScanResultsArr
+}
+
+// scans a file found while walking a directory and applies what its location says about it.
+// The category of its location takes precedence, ex: a minified file in node_modules is vendored.
+func scanFoundFile(file FileToScan) FileScanResults {
+	result := ScanFile(file.FilePath)
+	if file.Category != "" {
+		result.Category = file.Category
+	}
+	result.IsTest = file.IsTest
+	return result
+}
@@ -0,0 +1,90 @@
+package scanner
+
+import "strings"
+
+// conditionalGroup is an #if, #ifdef or #ifndef directive and the branches that follow it up to its #endif
+type conditionalGroup struct {
+	disabled bool // the current branch is disabled, ex: the lines after #if 0
+	taken    bool // a previous branch was enabled, so the rest of the branches are disabled
+}
+
+// conditionals tracks the #if 0 blocks of a file using the C preprocessor. Conditions other than constants,
+// ex: #ifdef DEBUG, are not evaluated and their branches are treated as enabled.
+type conditionals struct {
+	groups []conditionalGroup
+}
+
+// disabled returns true if the current line is inside of a disabled branch
+func (c *conditionals) disabled() bool {
+	for _, group := range c.groups {
+		if group.disabled {
+			return true
+		}
+	}
+	return false
+}
+
+// update follows the conditional directive the trimmed line holds, ex: #if 0, other lines are ignored
+func (c *conditionals) update(line []byte) {
+	if len(line) == 0 || line[0] != '#' {
+		return
+	}
+	// the directive name can be followed by a space or the condition, ex: #if(0)
+	rest := strings.TrimLeft(string(line[1:]), " \t")
+	end := strings.IndexFunc(rest, func(r rune) bool { return r < 'a' || r > 'z' })
+	if end == -1 {
+		end = len(rest)
+	}
+	directive, expression := rest[:end], rest[end:]
+	switch directive {
+	case "if", "ifdef", "ifndef":
+		value, known := constantCondition(expression)
+		if directive != "if" {
+			known = false
+		}
+		c.groups = append(c.groups, conditionalGroup{disabled: known && !value, taken: known && value})
+	case "elif", "elifdef", "elifndef":
+		if len(c.groups) == 0 {
+			return
+		}
+		group := &c.groups[len(c.groups)-1]
+		value, known := constantCondition(expression)
+		if directive != "elif" {
+			known = false
+		}
+		group.disabled = group.taken || (known && !value)
+		group.taken = group.taken || (known && value)
+	case "else":
+		if len(c.groups) == 0 {
+			return
+		}
+		group := &c.groups[len(c.groups)-1]
+		group.disabled = group.taken
+	case "endif":
+		if len(c.groups) > 0 {
+			c.groups = c.groups[:len(c.groups)-1]
+		}
+	}
+}
+
+// returns the value of a constant condition, ex: 0 or (false), and false if the condition is not a constant
+func constantCondition(expression string) (bool, bool) {
+	// comments after the condition are ignored, ex: #if 0 // disabled until the new API ships
+	if index := strings.Index(expression, "//"); index != -1 {
+		expression = expression[:index]
+	}
+	if index := strings.Index(expression, "/*"); index != -1 {
+		expression = expression[:index]
+	}
+	expression = strings.TrimSpace(expression)
+	for len(expression) > 1 && expression[0] == '(' && expression[len(expression)-1] == ')' {
+		expression = strings.TrimSpace(expression[1 : len(expression)-1])
+	}
+	switch expression {
+	case "0", "false":
+		return false, true
+	case "1", "true":
+		return true, true
+	}
+	return false, false
+}
@@ -2,22 +2,34 @@ package scanner
 
 import (
 	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"go-cloc/logger"
+	"hash"
 	"io"
 	"log"
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
+	"unicode"
 )
 
 type FileScanResults struct {
-	FilePath          string
-	LanguageName      string
-	TotalLines        int
-	CodeLineCount     int
-	BlankLineCount    int
-	CommentsLineCount int
+	FilePath                string
+	LanguageName            string
+	TotalLines              int
+	CodeLineCount           int
+	BlankLineCount          int
+	CommentsLineCount       int
+	SkipReason              string         // why the file was not counted, ex: binary file, empty if it was scanned
+	Category                string         // why the file is reported apart from the source code, ex: minified, empty for source code
+	IsTest                  bool           // the file is test code rather than main code, ex: src/test/java/AppTest.java
+	ContentHash             string         // SHA-256 of the file's content, only set if Options.HashContent is set
+	LineEnding              string         // line ending used by the file, ex: CRLF, mixed if it uses several, empty if it has a single line
+	LanguageToCodeLineCount map[string]int // code line count by language for files mixing languages, ex: the sections of a Vue component
 }
 type AnalyzeLineResult string
 
@@ -27,32 +39,419 @@ const (
 	BlankLine AnalyzeLineResult = "blankline"
 )
 
-func AnalyzeLine(line string, languageInfo LanguageInfo, isInBlockComment bool) (AnalyzeLineResult, bool) {
+// LineState is the lexical state carried over from one line of a file to the next
+type LineState struct {
+	BlockCommentEnd   string // the line starts inside a multi-line comment closed by this token, empty otherwise
+	BlockCommentDepth int    // how many levels deep the line starts inside of nested comments
+	StringDelimiter   string // the line starts inside a string literal closed by this delimiter, empty otherwise
+	InDocString       bool   // the string literal the line starts inside of is a docstring
+	MultiLineString   bool   // the string literal the line starts inside of can span lines, ex: a Go raw string
+	StringEscape      string // escapes the delimiter closing the multi-line string literal, empty if it has none
+	Heredoc           bool   // the string literal is a heredoc, closed by a line starting with StringDelimiter
+	BracketDepth      int    // open brackets carried over from previous lines, only tracked for languages with docstrings
+	ContinuedLine     bool   // the previous line ended with a line continuation, ex: x = \ in Python, only tracked for languages with docstrings
+}
+
+// InBlockComment returns true if the line starts inside a multi-line comment
+func (state LineState) InBlockComment() bool {
+	return state.BlockCommentEnd != ""
+}
+
+// ends the string literal the state is inside of
+func (state *LineState) endString() {
+	state.StringDelimiter = ""
+	state.InDocString = false
+	state.MultiLineString = false
+	state.StringEscape = ""
+	state.Heredoc = false
+}
+
+// AnalyzeLine classifies a single trimmed line and returns the state the next line starts in.
+// The line is walked character by character so that comment delimiters inside string and
+// character literals are ignored, ex: x = "/*"; is code and does not start a comment.
+func AnalyzeLine(line string, languageInfo LanguageInfo, state LineState) (AnalyzeLineResult, LineState) {
+	analyzer := newLineAnalyzer(languageInfo, state)
+	analyzer.matchPatterns([]byte(line))
+	analyzer.analyze([]byte(line), true)
+	return analyzer.endLine(), analyzer.state
+}
+
+// lineAnalyzer classifies a line that is fed to it in chunks, so that huge lines, ex: a bundled JavaScript file
+// on a single line, are classified without holding the whole line in memory
+type lineAnalyzer struct {
+	languageInfo  LanguageInfo
+	lookahead     int // the most bytes needed to match a token of the language
+	trackBrackets bool
+	classes       byteClasses
 
-	if isInBlockComment || beginsWithFirstMultiLineComment(line, languageInfo) {
-		// last characters on the line end a multi-line comment
-		if endsWithSecondMultiLineComment(line, languageInfo) {
-			return Comment, false
+	state LineState
+
+	// what has been found on the current line so far
+	analyzedBytes    int
+	hasCode          bool
+	hasComment       bool
+	escapedLineBreak bool
+	inLineComment    bool
+	patternResult    AnalyzeLineResult // how the line is classified by the line patterns of the language, empty if none matched
+}
+
+func newLineAnalyzer(languageInfo LanguageInfo, state LineState) *lineAnalyzer {
+	trackBrackets := len(languageInfo.DocStrings) > 0
+	analyzer := &lineAnalyzer{
+		languageInfo:  languageInfo,
+		lookahead:     longestTokenLength(languageInfo),
+		trackBrackets: trackBrackets,
+		classes:       newByteClasses(languageInfo, trackBrackets),
+		state:         state,
+	}
+	analyzer.startLine()
+	return analyzer
+}
+
+// resets what has been found on the current line, a blank line inside of a multi-line comment or string is part of it
+func (a *lineAnalyzer) startLine() {
+	docStringIsComment := a.state.InDocString && !Options.DocStringsAsCode
+	a.hasComment = a.state.InBlockComment() || docStringIsComment
+	a.hasCode = a.state.StringDelimiter != "" && !docStringIsComment
+	a.escapedLineBreak = false
+	a.inLineComment = false
+	a.analyzedBytes = 0
+	a.patternResult = ""
+}
+
+// makes the rest of the current line a comment, ex: a fixed-format COBOL line with a * in the indicator column
+func (a *lineAnalyzer) commentOutLine() {
+	a.hasComment = true
+	a.inLineComment = true
+}
+
+// matchPatterns classifies the whole line with the line patterns of the language, before it is analyzed. A line
+// matching a line comment pattern is a comment, ex: Fortran's C in column 1, so its tokens are not followed. Lines
+// matching a blank or ignore pattern are still analyzed to follow the literals and comments they open or close.
+// Lines starting inside of a multi-line comment or string literal are not matched.
+func (a *lineAnalyzer) matchPatterns(line []byte) {
+	if a.languageInfo.patterns == nil || a.state.InBlockComment() || a.state.StringDelimiter != "" {
+		return
+	}
+	a.patternResult = a.languageInfo.patterns.match(line)
+	if a.patternResult == Comment {
+		a.commentOutLine()
+	}
+}
+
+// returns true if nothing has been analyzed on the current line yet
+func (a *lineAnalyzer) atLineStart() bool {
+	return a.analyzedBytes == 0
+}
+
+// analyze walks the next chunk of the current line and returns how many of its bytes were analyzed.
+// Unless the chunk ends the line, the bytes at the end of the chunk that could be the start of a token are not
+// analyzed and must be passed again at the start of the next chunk.
+func (a *lineAnalyzer) analyze(text []byte, endOfLine bool) int {
+	languageInfo := a.languageInfo
+	state := &a.state
+
+	limit := len(text)
+	if !endOfLine {
+		limit = len(text) - a.lookahead + 1
+	}
+
+	i := 0
+	if state.Heredoc {
+		// a heredoc ends on a line starting with its identifier, the lines before it are part of the string
+		if a.atLineStart() && isHeredocEnd(text, state.StringDelimiter) {
+			i = len(state.StringDelimiter)
+			state.endString()
+		} else {
+			i = len(text)
 		}
-		// the multi-line comment continues
-		if !hasSecondMultiLineComment(line, languageInfo) {
-			return Comment, true
+	}
+	for i < limit && !a.inLineComment {
+		if state.Heredoc {
+			// the heredoc starts on the next line, the rest of the line it is opened on is code
+			a.hasCode = true
+			i = len(text)
+			break
+		}
+
+		// bytes that cannot start a token are skipped in one go, text outside of strings and comments is code
+		skippedText := false
+		if state.StringDelimiter != "" {
+			if state.InDocString && !Options.DocStringsAsCode {
+				a.hasComment = true
+			} else {
+				a.hasCode = true
+			}
+			i, skippedText = skipBytes(text, i, limit, &a.classes.inString)
+		} else if state.InBlockComment() {
+			i, skippedText = skipBytes(text, i, limit, &a.classes.inComment)
+		} else {
+			i, skippedText = skipBytes(text, i, limit, &a.classes.inCode)
+			a.hasCode = a.hasCode || skippedText
+		}
+		// an escaped line break is an escape character followed only by whitespace
+		if skippedText {
+			a.escapedLineBreak = false
+		}
+		if i >= limit {
+			break
+		}
+		if !isWhitespace(text[i]) {
+			a.escapedLineBreak = false
+		}
+
+		if state.MultiLineString {
+			// the escape only matters before the closing delimiter or another escape, ex: "" in a C# verbatim string
+			escape := state.StringEscape
+			if escape != "" && hasPrefix(text[i:], escape) && i+len(escape) < len(text) &&
+				(hasPrefix(text[i+len(escape):], escape) || text[i+len(escape)] == state.StringDelimiter[0]) {
+				i += len(escape) + 1
+			} else if hasPrefix(text[i:], state.StringDelimiter) {
+				i += len(state.StringDelimiter)
+				state.endString()
+			} else {
+				i++
+			}
+			continue
+		}
+
+		if state.StringDelimiter != "" {
+			if escape := languageInfo.EscapeCharacter; escape != "" && hasPrefix(text[i:], escape) {
+				// skip the escape character and the character it escapes
+				next := i + len(escape)
+				a.escapedLineBreak = next >= len(text) || isWhitespace(text[next])
+				i = next + 1
+			} else if hasPrefix(text[i:], state.StringDelimiter) {
+				i += len(state.StringDelimiter)
+				state.endString()
+			} else {
+				i++
+			}
+			continue
+		}
+
+		if state.InBlockComment() {
+			// only the partner of the token that started the comment can end it
+			if hasPrefix(text[i:], state.BlockCommentEnd) {
+				i += len(state.BlockCommentEnd)
+				state.BlockCommentDepth--
+				if state.BlockCommentDepth <= 0 {
+					state.BlockCommentEnd = ""
+					state.BlockCommentDepth = 0
+				}
+			} else if start := matchNestedCommentStart(text[i:], state.BlockCommentEnd, languageInfo); start != "" {
+				i += len(start)
+				state.BlockCommentDepth++
+			} else {
+				i++
+			}
+			continue
+		}
+
+		if isWhitespace(text[i]) {
+			i++
+		} else if pair := matchMultiLineCommentStart(text[i:], languageInfo); pair != nil {
+			a.hasComment = true
+			state.BlockCommentEnd = pair[1]
+			state.BlockCommentDepth = 1
+			i += len(pair[0])
+		} else if hasSingleLineComment(text[i:], languageInfo) {
+			// the rest of the line is a comment
+			a.hasComment = true
+			a.inLineComment = true
+		} else if delimiter, length := matchDocStringStart(text[i:], languageInfo); delimiter != "" {
+			// a string literal that starts a statement is a docstring, otherwise it is part of an expression
+			if !a.hasCode && state.BracketDepth == 0 && !state.ContinuedLine {
+				state.InDocString = true
+			}
+			if state.InDocString && !Options.DocStringsAsCode {
+				a.hasComment = true
+			} else {
+				a.hasCode = true
+			}
+			state.StringDelimiter = delimiter
+			i += length
+		} else if end, escape, length := matchMultiLineStringStart(text[i:], languageInfo); end != "" {
+			a.hasCode = true
+			state.StringDelimiter = end
+			state.MultiLineString = true
+			state.StringEscape = escape
+			i += length
+		} else if identifier, length := matchHeredocStart(text[i:], previousByte(text, i), languageInfo); identifier != "" {
+			a.hasCode = true
+			state.StringDelimiter = identifier
+			state.MultiLineString = true
+			state.Heredoc = true
+			i += length
+		} else if isDigitSeparator(text, i, languageInfo) {
+			a.hasCode = true
+			i += len(languageInfo.DigitSeparator)
+		} else if delimiter := matchStringDelimiter(text[i:], languageInfo); delimiter != "" {
+			a.hasCode = true
+			state.StringDelimiter = delimiter
+			i += len(delimiter)
+		} else if isRegexLiteralStart(text, i, languageInfo) {
+			// a regular expression literal is read like a string literal, so its quotes and backticks are ignored
+			a.hasCode = true
+			state.StringDelimiter = regexLiteralDelimiter
+			i += len(regexLiteralDelimiter)
 		} else {
-			// end of a mult-line comment is within the same line, therefore lines of code could be after this
-			splitLine := splitLineByFirstMultiLineComment(line, languageInfo)[1]
-			return AnalyzeLine(splitLine, languageInfo, false)
+			if a.trackBrackets {
+				state.BracketDepth = updateBracketDepth(state.BracketDepth, text[i])
+				// an escape character followed only by whitespace continues the statement on the next line
+				if escape := languageInfo.EscapeCharacter; escape != "" && hasPrefix(text[i:], escape) {
+					next := i + len(escape)
+					a.escapedLineBreak = next >= len(text) || isWhitespace(text[next])
+				}
+			}
+			a.hasCode = true
+			i++
 		}
+	}
+
+	if a.inLineComment || i > len(text) {
+		i = len(text)
+	}
+	a.analyzedBytes += i
+	return i
+}
 
-		// if not, then it could be code, need to recurse in
+// skipBytes returns the index of the next byte from i that can start a token, or the limit if there is none,
+// and true if any of the bytes skipped were not whitespace
+func skipBytes(text []byte, i int, limit int, classes *[256]byteClass) (int, bool) {
+	skippedText := false
+	for ; i < limit; i++ {
+		switch classes[text[i]] {
+		case tokenStartByte:
+			return i, skippedText
+		case otherByte:
+			skippedText = true
+		}
+	}
+	return i, skippedText
+}
 
-	} else if isBlankLine(line) {
-		return BlankLine, false
-	} else if hasSingleLineComment(line, languageInfo) {
-		return Comment, false
+// endLine classifies the current line once all of its chunks have been analyzed and starts the next line
+func (a *lineAnalyzer) endLine() AnalyzeLineResult {
+	// string literals end with the line unless the line break is escaped or they can span multiple lines
+	if !a.escapedLineBreak && !a.state.MultiLineString && !slices.Contains(a.languageInfo.DocStrings, a.state.StringDelimiter) {
+		a.state.endString()
 	}
-	// it must be code
-	return Code, false
+	a.state.ContinuedLine = a.trackBrackets && a.escapedLineBreak && a.state.StringDelimiter == ""
+
+	// a line with any code on it is code, even if a comment starts, ends or continues on it
+	result := BlankLine
+	if a.patternResult != "" {
+		result = a.patternResult
+	} else if a.hasCode {
+		result = Code
+	} else if a.hasComment {
+		result = Comment
+	}
+	a.startLine()
+	return result
+}
+
+// byteClass is what a byte can be the start of in a lexical state
+type byteClass uint8
+
+const (
+	otherByte byteClass = iota
+	whitespaceByte
+	tokenStartByte // the byte may start a token of the language and must be compared to its tokens
+)
 
+// byteClasses are the classes of every byte in each lexical state, built once per file from the tokens of its
+// language so that runs of bytes that cannot start a token are skipped without comparing them to every token
+type byteClasses struct {
+	inCode    [256]byteClass
+	inString  [256]byteClass
+	inComment [256]byteClass
+}
+
+func newByteClasses(languageInfo LanguageInfo, trackBrackets bool) byteClasses {
+	var classes byteClasses
+	for _, table := range []*[256]byteClass{&classes.inCode, &classes.inString, &classes.inComment} {
+		for _, c := range []byte{' ', '\t', '\r', '\n', '\v', '\f'} {
+			table[c] = whitespaceByte
+		}
+	}
+	addTokenStarts := func(table *[256]byteClass, tokens ...string) {
+		for _, token := range tokens {
+			if token != "" {
+				table[token[0]] = tokenStartByte
+			}
+		}
+	}
+
+	addTokenStarts(&classes.inCode, languageInfo.LineComments...)
+	addTokenStarts(&classes.inCode, languageInfo.StringDelimiters...)
+	addTokenStarts(&classes.inCode, languageInfo.DocStrings...)
+	for _, prefix := range languageInfo.StringPrefixes {
+		// prefixes are case insensitive
+		addTokenStarts(&classes.inCode, strings.ToLower(prefix), strings.ToUpper(prefix))
+	}
+	for _, pair := range slices.Concat(languageInfo.MultiLineComments, languageInfo.NestedComments) {
+		addTokenStarts(&classes.inCode, pair...)
+		addTokenStarts(&classes.inComment, pair...)
+	}
+	if trackBrackets {
+		addTokenStarts(&classes.inCode, "(", "[", "{", ")", "]", "}", languageInfo.EscapeCharacter)
+	}
+	addTokenStarts(&classes.inCode, languageInfo.Heredocs...)
+
+	addTokenStarts(&classes.inString, languageInfo.EscapeCharacter)
+	addTokenStarts(&classes.inString, languageInfo.StringDelimiters...)
+	addTokenStarts(&classes.inString, languageInfo.DocStrings...)
+	if languageInfo.RegexLiterals {
+		addTokenStarts(&classes.inString, regexLiteralDelimiter)
+	}
+	for _, multiLineString := range languageInfo.MultiLineStrings {
+		addTokenStarts(&classes.inCode, multiLineString[0])
+		// the string can be closed by a custom delimiter, so any byte can start the closing delimiter
+		if strings.HasPrefix(multiLineString[1], customDelimiter) {
+			for c := range classes.inString {
+				if classes.inString[c] == otherByte {
+					classes.inString[c] = tokenStartByte
+				}
+			}
+		}
+		addTokenStarts(&classes.inString, multiLineString[1:]...)
+	}
+	return classes
+}
+
+// returns the most bytes needed to match a token of the language, ex: 3 for Python's """ docstrings
+func longestTokenLength(languageInfo LanguageInfo) int {
+	// an escape character is matched along with the character it escapes
+	longest := len(languageInfo.EscapeCharacter) + 1
+	tokens := slices.Concat(languageInfo.LineComments, languageInfo.StringDelimiters)
+	for _, pair := range slices.Concat(languageInfo.MultiLineComments, languageInfo.NestedComments) {
+		tokens = append(tokens, pair...)
+	}
+	longestPrefix := 0
+	for _, prefix := range languageInfo.StringPrefixes {
+		longestPrefix = max(longestPrefix, len(prefix))
+	}
+	for _, delimiter := range languageInfo.DocStrings {
+		longest = max(longest, longestPrefix+len(delimiter))
+	}
+	for _, multiLineString := range languageInfo.MultiLineStrings {
+		for _, token := range multiLineString {
+			// a custom delimiter can be as long as the longest allowed
+			longest = max(longest, len(strings.ReplaceAll(token, customDelimiter, strings.Repeat(" ", maxCustomDelimiterLength)))+1)
+		}
+	}
+	for _, operator := range languageInfo.Heredocs {
+		// the operator can be followed by a space, a backslash and a quote
+		longest = max(longest, len(operator)+3)
+	}
+	// a digit separator is matched along with the digit following it
+	longest = max(longest, len(languageInfo.DigitSeparator)+1)
+	for _, token := range tokens {
+		longest = max(longest, len(token))
+	}
+	return longest
 }
 
 func ScanFile(filePath string) FileScanResults {
@@ -75,6 +474,7 @@ func ScanFile(filePath string) FileScanResults {
 		logger.Error("File ", filePath, " failed to scan. Counting as 0")
 		logger.Error(err)
 		logger.Error(logger.GetStackTrace())
+		result.SkipReason = SkipReasonUnreadable + ": " + err.Error()
 		return result
 	}
 	defer f.Close()
@@ -89,40 +489,161 @@ func ScanFile(filePath string) FileScanResults {
 		foundLanguageInfo := false
 		langName, languageInfo, foundLanguageInfo = LookupByFileName(fileName)
 		if !foundLanguageInfo {
-			logger.Debug("Skipping file: ", fileName, " suffix '", suffix, "'. No suffix and file name not supported in config.")
+			// scripts without a suffix declare their interpreter on the first line, ex: #!/usr/bin/env python3
+			langName, languageInfo, foundLanguageInfo = LookupByShebang(filePath)
+		}
+		if !foundLanguageInfo {
+			logger.Debug("Skipping file: ", fileName, " suffix '", suffix, "'. No suffix, file name and interpreter not supported in config.")
+			result.SkipReason = SkipReasonUnsupportedLanguage
 			return result
 		}
 
 	} else {
 		foundLanguageInfo := false
-		langName, languageInfo, foundLanguageInfo = LookupByExtension(suffix)
-		// If not supported return 0s, TODO should probably throw an error or report on it
-		// TODO Dockerfile does not always have an extension, but we will count it
+		langName, languageInfo, foundLanguageInfo = LookupByExtensionAndContent(suffix, filePath)
+		// If not supported return 0s and report why
 		if !foundLanguageInfo {
 			logger.Debug("Skipping file: ", fileName, " suffix '", suffix, "' is not supported.")
+			result.SkipReason = SkipReasonUnsupportedLanguage
 			return result
 		}
 	}
 
-	// Scan file
-	reader := bufio.NewReader(f)
-	isInBlockComment := false
-	debugLineNum := 1
-	for {
+	// the raw content is hashed while it is read so that files with identical content can be counted once
+	var source io.Reader = f
+	var contentHash hash.Hash
+	if Options.HashContent {
+		contentHash = sha256.New()
+		source = io.TeeReader(f, contentHash)
+	}
+
+	// files are decoded to UTF-8 first, ex: UTF-16 sources with a byte order mark would otherwise look binary
+	reader, encoding := DecodeText(bufio.NewReaderSize(source, binarySniffLength), Options.FallbackEncoding)
+	if encoding != EncodingUTF8 {
+		logger.Debug("Decoding file: ", fileName, " from ", encoding)
+	}
+
+	// binary files are not counted even if their suffix is supported, ex: a compiled file renamed to .cls
+	head, _ := reader.Peek(binarySniffLength)
+	if IsBinary(head) {
+		logger.Debug("Skipping file: ", fileName, " is a binary file.")
+		result.SkipReason = SkipReasonBinary
+		return result
+	}
 
-		line, err := reader.ReadString('\n')
-		line = strings.TrimSpace(line)
+	// minified and generated files are counted but reported apart from the source code, ex: app.min.js.
+	// Notebooks are not classified from their raw JSON, whose image outputs would look minified.
+	category := ""
+	if langName != JupyterNotebook {
+		category = ClassifyContent(head)
+	}
+	if category != "" {
+		logger.Debug("File: ", fileName, " is ", category)
+	}
+
+	if langName == JupyterNotebook {
+		// Jupyter notebooks are JSON documents, only the source of their cells is scanned
+		result = ScanNotebook(reader, filePath)
+	} else if langName == JCL {
+		// JCL statements are recognized by the start of each line and can be followed by in-stream data
+		result = ScanJCL(reader, filePath)
+	} else if singleFileComponentLanguages[langName] {
+		// single-file components are split into sections which are scanned with the rules of their own language
+		result = ScanSingleFileComponent(reader, filePath, langName)
+	} else {
+		// Scan file
+		codeLineCount, commentsLineCount, blankLineCount, result.LineEnding = scanLines(reader, languageInfo)
+		totalLines = codeLineCount + commentsLineCount + blankLineCount
 
-		lineResult, blockCommentContinuesToNexLine := AnalyzeLine(line, languageInfo, isInBlockComment)
-		if lineResult == Code {
-			isInBlockComment = false
-			codeLineCount++
-		} else if lineResult == BlankLine {
-			isInBlockComment = false
-			blankLineCount++
-		} else if lineResult == Comment {
-			isInBlockComment = blockCommentContinuesToNexLine
-			commentsLineCount++
+		// return the totals
+		result.TotalLines = totalLines
+		result.CodeLineCount = codeLineCount
+		result.BlankLineCount = blankLineCount
+		result.CommentsLineCount = commentsLineCount
+		result.LanguageName = langName
+		result.FilePath = filePath
+	}
+	result.Category = category
+	if contentHash != nil {
+		result.ContentHash = hex.EncodeToString(contentHash.Sum(nil))
+	}
+	return result
+
+}
+
+// scanLines classifies every line read from the reader and returns the number of code, comment and blank lines
+// followed by the line ending of the lines, ex: CRLF. Lines are classified in chunks so that the memory used
+// does not grow with the length of a line, and without allocating for each line.
+func scanLines(r io.Reader, languageInfo LanguageInfo) (int, int, int, string) {
+	commentsLineCount := 0
+	codeLineCount := 0
+	blankLineCount := 0
+
+	reader := newLineReader(r)
+	defer reader.release()
+	analyzer := newLineAnalyzer(languageInfo, LineState{})
+	columnRules := hasColumnRules(languageInfo)
+	// the end of the previous chunk that was not analyzed yet, since it could be the start of a token
+	var pending []byte
+	// the start of the line without the columns that are not counted, ex: sequence numbers of fixed-format COBOL
+	var columns []byte
+	// the #if 0 blocks are only followed when they are counted as comments
+	trackConditionals := Options.DisabledCodeAsComments && languageInfo.Preprocessor
+	var conditionals conditionals
+	for {
+		chunk, endOfLine, err := reader.readChunk(lineChunkSize)
+		text := chunk
+		if len(pending) > 0 {
+			pending = append(pending, chunk...)
+			text = pending
+		} else if analyzer.atLineStart() {
+			// the patterns match the whole raw line, a line longer than a chunk is only classified by its tokens
+			if endOfLine {
+				analyzer.matchPatterns(chunk)
+			}
+			// column rules apply to the raw line, the columns are lost once the line is trimmed
+			if columnRules && isFreeFormatDirective(text, languageInfo) {
+				columnRules = false
+			}
+			if columnRules {
+				var isComment bool
+				columns, isComment = applyColumnRules(columns[:0], text, languageInfo)
+				text = columns
+				if isComment {
+					analyzer.commentOutLine()
+				}
+				// a directive can also follow the sequence numbers of a fixed-format line, ex: 000100 >>SOURCE FORMAT FREE
+				if !isComment && isFreeFormatDirective(columns, languageInfo) {
+					columnRules = false
+				}
+			}
+			// leading and trailing whitespace are ignored, the same as for a trimmed line
+			text = bytes.TrimLeftFunc(text, unicode.IsSpace)
+			if trackConditionals && analyzer.state.BlockCommentEnd == "" && analyzer.state.StringDelimiter == "" {
+				// the directives opening and closing a disabled block are code, the lines between them are not
+				disabledBefore := conditionals.disabled()
+				conditionals.update(text)
+				if disabledBefore && conditionals.disabled() && len(bytes.TrimSpace(text)) > 0 {
+					analyzer.commentOutLine()
+				}
+			}
+		}
+		if endOfLine {
+			text = bytes.TrimRightFunc(text, unicode.IsSpace)
+		}
+		analyzed := analyzer.analyze(text, endOfLine)
+		// the chunk is only valid until the next read, the bytes held back are copied
+		pending = append(pending[:0], text[analyzed:]...)
+
+		if endOfLine {
+			lineResult := analyzer.endLine()
+			if lineResult == Code {
+				codeLineCount++
+			} else if lineResult == BlankLine {
+				blankLineCount++
+			} else if lineResult == Comment {
+				commentsLineCount++
+			}
 		}
 
 		if err != nil {
@@ -132,63 +653,274 @@ func ScanFile(filePath string) FileScanResults {
 			}
 			logger.LogStackTraceAndExit(err)
 		}
-		debugLineNum++
 	}
-
-	// return the totals
-	result.TotalLines = totalLines
-	result.CodeLineCount = codeLineCount
-	result.BlankLineCount = blankLineCount
-	result.CommentsLineCount = commentsLineCount
-	result.LanguageName = langName
-	result.FilePath = filePath
-	return result
-
+	return codeLineCount, commentsLineCount, blankLineCount, reader.lineEnding()
 }
 
 /*
 *
 @singleLineCommentPrefix is something "/" or "//" or "#"
 */
-func hasSingleLineComment(line string, languageInfo LanguageInfo) bool {
+func hasSingleLineComment(line []byte, languageInfo LanguageInfo) bool {
+	// code that starts like a comment, ex: PHP's #[Route("/")] attribute
+	for _, exception := range languageInfo.CommentExceptions {
+		if hasPrefix(line, exception) {
+			return false
+		}
+	}
 	for _, singleLineCommentPrefix := range languageInfo.LineComments {
-		return strings.HasPrefix(line, singleLineCommentPrefix)
+		if hasPrefix(line, singleLineCommentPrefix) {
+			return true
+		}
 	}
 	return false
 }
 
-func beginsWithFirstMultiLineComment(line string, languageInfo LanguageInfo) bool {
+// returns the multi-line comment pair whose start token the line begins with, nil if there is none
+func matchMultiLineCommentStart(line []byte, languageInfo LanguageInfo) []string {
 	for _, pair := range languageInfo.MultiLineComments {
-		firstMultiLineCommentToken := pair[0]
-		return strings.HasPrefix(line, firstMultiLineCommentToken)
+		if len(pair) == 2 && hasPrefix(line, pair[0]) {
+			return pair
+		}
 	}
-	return false
+	for _, pair := range languageInfo.NestedComments {
+		if len(pair) == 2 && hasPrefix(line, pair[0]) {
+			return pair
+		}
+	}
+	return nil
 }
 
-func hasSecondMultiLineComment(line string, languageInfo LanguageInfo) bool {
-	for _, pair := range languageInfo.MultiLineComments {
-		secondMultiLineCommentToken := pair[1]
-		return strings.Contains(line, secondMultiLineCommentToken)
+// returns the start token of the nested comment ended by endToken if the line begins with it, empty if there is none
+func matchNestedCommentStart(line []byte, endToken string, languageInfo LanguageInfo) string {
+	for _, pair := range languageInfo.NestedComments {
+		if len(pair) == 2 && pair[1] == endToken && hasPrefix(line, pair[0]) {
+			return pair[0]
+		}
 	}
-	return false
+	return ""
 }
-func endsWithSecondMultiLineComment(line string, languageInfo LanguageInfo) bool {
-	for _, pair := range languageInfo.MultiLineComments {
-		secondMultiLineCommentToken := pair[1]
-		return strings.HasSuffix(line, secondMultiLineCommentToken)
+
+// returns the string literal delimiter the line begins with, empty if there is none
+func matchStringDelimiter(line []byte, languageInfo LanguageInfo) string {
+	for _, delimiter := range languageInfo.StringDelimiters {
+		if hasPrefix(line, delimiter) {
+			return delimiter
+		}
 	}
-	return false
+	return ""
 }
-func splitLineByFirstMultiLineComment(line string, languageInfo LanguageInfo) []string {
-	for _, pair := range languageInfo.MultiLineComments {
-		secondMultiLineCommentToken := pair[1]
-		return strings.SplitN(line, secondMultiLineCommentToken, 2)
+
+// returns the docstring delimiter the line begins with, optionally preceded by a string prefix, and the length of both
+func matchDocStringStart(line []byte, languageInfo LanguageInfo) (string, int) {
+	for _, delimiter := range languageInfo.DocStrings {
+		if hasPrefix(line, delimiter) {
+			return delimiter, len(delimiter)
+		}
+		for _, prefix := range languageInfo.StringPrefixes {
+			// prefixes are case insensitive, ex: r""" and R""" are both raw strings
+			if len(line) > len(prefix) && equalFoldASCII(line[:len(prefix)], prefix) && hasPrefix(line[len(prefix):], delimiter) {
+				return delimiter, len(prefix) + len(delimiter)
+			}
+		}
 	}
-	return []string{}
+	return "", 0
 }
 
-func isBlankLine(line string) bool {
-	return len(line) == 0
+// returns true if the text begins with the prefix, the comparison does not allocate
+func hasPrefix(text []byte, prefix string) bool {
+	return len(text) >= len(prefix) && string(text[:len(prefix)]) == prefix
+}
+
+// returns true if the text is equal to the ASCII word ignoring case, ex: Rb and rB
+func equalFoldASCII(text []byte, word string) bool {
+	if len(text) != len(word) {
+		return false
+	}
+	for i := range text {
+		if toLowerASCII(text[i]) != toLowerASCII(word[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func toLowerASCII(character byte) byte {
+	if 'A' <= character && character <= 'Z' {
+		return character + 'a' - 'A'
+	}
+	return character
+}
+
+// stands for the custom delimiter of a multi-line string literal, ex: R"{delimiter}( and ){delimiter}" for C++ raw strings
+const customDelimiter = "{delimiter}"
+
+// the longest custom delimiter of a multi-line string literal, the limit for C++ raw strings
+const maxCustomDelimiterLength = 16
+
+// returns the closing delimiter and the escape of the multi-line string literal the line begins with, along with the
+// length of its opening delimiter. The closing delimiter is empty if the line does not begin with one.
+func matchMultiLineStringStart(line []byte, languageInfo LanguageInfo) (string, string, int) {
+	for _, multiLineString := range languageInfo.MultiLineStrings {
+		start, end := multiLineString[0], multiLineString[1]
+		prefix, suffix, hasCustomDelimiter := strings.Cut(start, customDelimiter)
+		if !hasPrefix(line, prefix) {
+			continue
+		}
+		length := len(prefix)
+		if hasCustomDelimiter {
+			delimiterLength := customDelimiterLength(line[length:], suffix)
+			if delimiterLength < 0 {
+				continue
+			}
+			end = strings.ReplaceAll(end, customDelimiter, string(line[length:length+delimiterLength]))
+			length += delimiterLength + len(suffix)
+		}
+		escape := ""
+		if len(multiLineString) > 2 {
+			escape = multiLineString[2]
+		}
+		return end, escape, length
+	}
+	return "", "", 0
+}
+
+// returns the length of the custom delimiter the text begins with, which must be followed by suffix, -1 if there is
+// none. A custom delimiter cannot contain whitespace, parentheses or backslashes.
+func customDelimiterLength(text []byte, suffix string) int {
+	for length := 0; length <= maxCustomDelimiterLength && length <= len(text); length++ {
+		if hasPrefix(text[length:], suffix) {
+			return length
+		}
+		if length == len(text) || isWhitespace(text[length]) || strings.IndexByte("()\\", text[length]) != -1 {
+			return -1
+		}
+	}
+	return -1
+}
+
+// returns the identifier of the heredoc the line begins with and the length of the operator and identifier, ex: EOS
+// for <<~'EOS'. The identifier can be quoted, and escaped with a backslash in shell scripts. An ambiguous operator,
+// ex: Ruby's << which also appends, only starts a heredoc on an uppercase or quoted identifier and when it does not
+// directly follow an operand, ex: items<<item is an append.
+func matchHeredocStart(line []byte, previous byte, languageInfo LanguageInfo) (string, int) {
+	for _, operator := range languageInfo.Heredocs {
+		if !hasPrefix(line, operator) {
+			continue
+		}
+		ambiguous := slices.Contains(languageInfo.AmbiguousHeredocs, operator)
+		if ambiguous && (isIdentifierByte(previous) || previous == ')' || previous == ']' || previous == '}') {
+			continue
+		}
+		i := len(operator)
+		if languageInfo.HeredocWhitespace {
+			for i < len(line) && (line[i] == ' ' || line[i] == '\t') {
+				i++
+			}
+		}
+		if i < len(line) && line[i] == '\\' {
+			i++
+		}
+		quote := byte(0)
+		if i < len(line) && (line[i] == '\'' || line[i] == '"' || line[i] == '`') {
+			quote = line[i]
+			i++
+		}
+		start := i
+		for i < len(line) && isIdentifierByte(line[i]) && !(i == start && '0' <= line[i] && line[i] <= '9') {
+			i++
+		}
+		if i == start || (ambiguous && quote == 0 && !('A' <= line[start] && line[start] <= 'Z')) {
+			continue
+		}
+		identifier := string(line[start:i])
+		if quote != 0 {
+			if i == len(line) || line[i] != quote {
+				continue
+			}
+			i++
+		}
+		return identifier, i
+	}
+	return "", 0
+}
+
+// returns true if the digit separator of the language is at the index of the text, inside of a number literal. It must
+// be followed by a digit or a hex digit and the word before it must start with a digit, ex: 1'000 or 0xFF'FF but not
+// case'a'.
+func isDigitSeparator(text []byte, i int, languageInfo LanguageInfo) bool {
+	separator := languageInfo.DigitSeparator
+	next := i + len(separator)
+	if separator == "" || !hasPrefix(text[i:], separator) || next >= len(text) || !isHexDigit(text[next]) {
+		return false
+	}
+	start := i
+	for start > 0 && (isIdentifierByte(text[start-1]) || hasPrefix(text[start-1:], separator)) {
+		start--
+	}
+	return start < i && '0' <= text[start] && text[start] <= '9'
+}
+
+func isHexDigit(character byte) bool {
+	return ('0' <= character && character <= '9') || ('a' <= character && character <= 'f') || ('A' <= character && character <= 'F')
+}
+
+// delimits the regular expression literals of languages with RegexLiterals, ex: /`/g
+const regexLiteralDelimiter = "/"
+
+// returns true if a regular expression literal starts at the index of the text. The / must follow one of the
+// operators or keywords after which a division is not possible, ex: ( = , or return, on the same line.
+func isRegexLiteralStart(text []byte, i int, languageInfo LanguageInfo) bool {
+	if !languageInfo.RegexLiterals || !hasPrefix(text[i:], regexLiteralDelimiter) {
+		return false
+	}
+	before := bytes.TrimRight(text[:i], " \t")
+	if len(before) == 0 {
+		return false
+	}
+	if strings.IndexByte("(,=:[!&|?{};", before[len(before)-1]) != -1 {
+		return true
+	}
+	keyword := before[max(0, len(before)-len("return")):]
+	return string(keyword) == "return" && !isIdentifierByte(previousByte(before, len(before)-len("return")))
+}
+
+// returns the byte before the index of the text, 0 at the start of the text
+func previousByte(text []byte, i int) byte {
+	if i == 0 {
+		return 0
+	}
+	return text[i-1]
+}
+
+// returns true if the trimmed line closes the heredoc, the identifier can be followed by punctuation, ex: EOT; in PHP
+func isHeredocEnd(line []byte, identifier string) bool {
+	if !hasPrefix(line, identifier) {
+		return false
+	}
+	rest := line[len(identifier):]
+	return len(rest) == 0 || !(isIdentifierByte(rest[0]) || isWhitespace(rest[0]))
+}
+
+func isIdentifierByte(character byte) bool {
+	return character == '_' || ('a' <= character && character <= 'z') || ('A' <= character && character <= 'Z') || ('0' <= character && character <= '9')
+}
+
+// returns the bracket depth after the character
+func updateBracketDepth(depth int, character byte) int {
+	switch character {
+	case '(', '[', '{':
+		return depth + 1
+	case ')', ']', '}':
+		if depth > 0 {
+			return depth - 1
+		}
+	}
+	return depth
+}
+
+func isWhitespace(character byte) bool {
+	return character == ' ' || character == '\t' || character == '\r' || character == '\n' || character == '\v' || character == '\f'
 }
 
 func loadIgnorePatterns(patterns []string) []*regexp.Regexp {
@@ -243,7 +975,31 @@ func ParseFileSuffix(fileName string) string {
 	return ""
 }
 
-func WalkDirectory(targetPath string, ignorePatterns []string) []string {
+// WalkDirectory walks the target path and returns every file path that is supported by the languages configuration
+func WalkDirectory(targetPath string, ignorePatterns []string) []FileToScan {
+	filesChannel := make(chan FileToScan)
+	go StreamDirectory(targetPath, ignorePatterns, filesChannel)
+
+	files := []FileToScan{}
+	for file := range filesChannel {
+		files = append(files, file)
+	}
+	return files
+}
+
+// FileToScan is a supported file found while walking a directory
+type FileToScan struct {
+	FilePath string
+	Category string // category of the location the file was found in, ex: vendored, empty for source code
+	IsTest   bool   // the file is test code based on its location and name, ex: __tests__/app.js
+}
+
+// StreamDirectory walks the target path and sends every supported file to the channel as soon as it is found.
+// Files inside of a vendored directory relative to the target path, ex: node_modules, are sent with the vendored category
+// and test code is flagged based on its path relative to the target path, ex: src/test/.
+// The channel is closed once the walk has finished, which allows scanning to start before the walk completes.
+func StreamDirectory(targetPath string, ignorePatterns []string, filePaths chan<- FileToScan) {
+	defer close(filePaths)
 	patterns := loadIgnorePatterns(ignorePatterns)
 
 	// Store the current working directory
@@ -253,7 +1009,6 @@ func WalkDirectory(targetPath string, ignorePatterns []string) []string {
 	}
 
 	logger.Debug("Target directory is ", targetPath)
-	var filePaths []string
 	err = filepath.WalkDir(targetPath, func(path string, info os.DirEntry, err error) error {
 		if err != nil {
 			return err
@@ -281,12 +1036,28 @@ func WalkDirectory(targetPath string, ignorePatterns []string) []string {
 			var found bool
 			if suffix == "" {
 				_, _, found = LookupByFileName(filepath.Base(info.Name()))
+				// files inside of tool directories are not sniffed for a shebang, ex: the objects of .git
+				relativePath, err := filepath.Rel(targetPath, path)
+				if !found && (err != nil || !IsInDotDirectory(relativePath)) {
+					_, _, found = LookupByShebang(path)
+				}
 			} else {
 				_, _, found = LookupByExtension(suffix)
 			}
 
 			if found {
-				filePaths = append(filePaths, absPath)
+				file := FileToScan{FilePath: absPath}
+				if relativePath, err := filepath.Rel(targetPath, path); err == nil {
+					// the target path is the file itself
+					if relativePath == "." {
+						relativePath = info.Name()
+					}
+					if IsVendoredPath(relativePath) {
+						file.Category = CategoryVendored
+					}
+					file.IsTest = IsTestPath(relativePath)
+				}
+				filePaths <- file
 			} else {
 				logger.Debug("Skipping file - ", path, " suffix - ", suffix, " - not supported")
 			}
@@ -303,6 +1074,4 @@ func WalkDirectory(targetPath string, ignorePatterns []string) []string {
 	if err != nil {
 		logger.Debug("Error changing back to the original directory:", err)
 	}
-
-	return filePaths
 }
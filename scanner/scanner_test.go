@@ -1,8 +1,15 @@
 package scanner
 
 import (
+	"bufio"
+	"encoding/json"
 	"fmt"
 	"go-cloc/logger"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -44,6 +51,15 @@ func Test_scanner_ScanFile_c_evil(t *testing.T) {
 
 }
 
+func Test_scanner_ScanFile_c_mid_line_comments(t *testing.T) {
+	result := ScanFile("test-files/c/mid-line.c")
+
+	// Assert
+	assert.Equal(t, 6, result.CodeLineCount)
+	assert.Equal(t, 4, result.CommentsLineCount)
+	assert.Equal(t, 1, result.BlankLineCount)
+}
+
 func Test_scanner_ScanFile_cpp_hard(t *testing.T) {
 	result := ScanFile("test-files/cpp/hard.cpp")
 
@@ -64,14 +80,194 @@ func Test_scanner_ScanFile_cpp_evil(t *testing.T) {
 
 }
 
+func Test_scanner_ScanFile_pascal_comments(t *testing.T) {
+	result := ScanFile("test-files/pascal/comments.pas")
+
+	// Assert
+	assert.Equal(t, 4, result.CodeLineCount)
+	assert.Equal(t, 6, result.CommentsLineCount)
+	assert.Equal(t, 1, result.BlankLineCount)
+}
+
+func Test_scanner_ScanFile_php_comments(t *testing.T) {
+	result := ScanFile("test-files/php/comments.php")
+
+	// Assert
+	assert.Equal(t, 3, result.CodeLineCount)
+	assert.Equal(t, 4, result.CommentsLineCount)
+	assert.Equal(t, 1, result.BlankLineCount)
+}
+
+func Test_scanner_ScanFile_razor_comment_tokens_inside_attributes(t *testing.T) {
+	result := ScanFile("test-files/razor/upload.cshtml")
+
+	// Assert
+	assert.Equal(t, "Razor", result.LanguageName)
+	assert.Equal(t, 5, result.CodeLineCount)
+	assert.Equal(t, 1, result.CommentsLineCount)
+	assert.Equal(t, 1, result.BlankLineCount)
+}
+
+func Test_scanner_AnalyzeLine_only_matching_end_token_closes_comment(t *testing.T) {
+	_, languageInfo, _ := LookupByExtension(".razor")
+
+	result, state := AnalyzeLine("@* razor comment containing */ and -->", languageInfo, LineState{})
+
+	// Assert
+	assert.Equal(t, Comment, result)
+	assert.Equal(t, "*@", state.BlockCommentEnd)
+
+	result, state = AnalyzeLine("still a comment *@ <p>markup</p>", languageInfo, state)
+	assert.Equal(t, Code, result)
+	assert.Equal(t, LineState{}, state)
+}
+
+func Test_scanner_ScanFile_swift_nested_comments(t *testing.T) {
+	result := ScanFile("test-files/swift/nested.swift")
+
+	// Assert
+	assert.Equal(t, 2, result.CodeLineCount)
+	assert.Equal(t, 4, result.CommentsLineCount)
+	assert.Equal(t, 1, result.BlankLineCount)
+}
+
+func Test_scanner_AnalyzeLine_nested_comments(t *testing.T) {
+	_, languageInfo, _ := LookupByExtension(".d")
+
+	// /* */ comments do not nest in D
+	result, state := AnalyzeLine("/* /* */", languageInfo, LineState{})
+	assert.Equal(t, Comment, result)
+	assert.Equal(t, LineState{}, state)
+
+	// /+ +/ comments do
+	result, state = AnalyzeLine("/+ /+ +/", languageInfo, LineState{})
+	assert.Equal(t, Comment, result)
+	assert.Equal(t, LineState{BlockCommentEnd: "+/", BlockCommentDepth: 1}, state)
+
+	result, state = AnalyzeLine("+/ int x;", languageInfo, state)
+	assert.Equal(t, Code, result)
+	assert.Equal(t, LineState{}, state)
+}
+
+func Test_scanner_ScanFile_python_docstrings(t *testing.T) {
+	result := ScanFile("test-files/python/docstrings.py")
+
+	// Assert
+	assert.Equal(t, 10, result.CodeLineCount)
+	assert.Equal(t, 8, result.CommentsLineCount)
+	assert.Equal(t, 6, result.BlankLineCount)
+}
+
+func Test_scanner_ScanFile_python_docstrings_as_code(t *testing.T) {
+	Options.DocStringsAsCode = true
+	defer func() { Options.DocStringsAsCode = false }()
+
+	result := ScanFile("test-files/python/docstrings.py")
+
+	// Assert
+	assert.Equal(t, 18, result.CodeLineCount)
+	assert.Equal(t, 0, result.CommentsLineCount)
+	assert.Equal(t, 6, result.BlankLineCount)
+}
+
+func Test_scanner_AnalyzeLine_python_string_after_line_continuation_is_not_docstring(t *testing.T) {
+	_, languageInfo, _ := LookupByExtension(".py")
+
+	result, state := AnalyzeLine("x = \\", languageInfo, LineState{})
+
+	// Assert
+	assert.Equal(t, Code, result)
+	assert.Equal(t, true, state.ContinuedLine)
+
+	result, state = AnalyzeLine(`"""a string`, languageInfo, state)
+	assert.Equal(t, Code, result)
+	assert.Equal(t, false, state.InDocString)
+
+	result, state = AnalyzeLine(`# still the string"""`, languageInfo, state)
+	assert.Equal(t, Code, result)
+	assert.Equal(t, LineState{}, state)
+
+	result, _ = AnalyzeLine(`"""a docstring"""`, languageInfo, state)
+	assert.Equal(t, Comment, result)
+}
+
+func Test_scanner_ScanFile_jupyter_notebook(t *testing.T) {
+	result := ScanFile("test-files/notebook/analysis.ipynb")
+
+	// Assert
+	assert.Equal(t, "Python", result.LanguageName)
+	assert.Equal(t, 3, result.CodeLineCount)
+	assert.Equal(t, 2, result.CommentsLineCount)
+	assert.Equal(t, 0, result.BlankLineCount)
+}
+
+func Test_scanner_ScanFile_jupyter_notebook_markdown_as_comments(t *testing.T) {
+	Options.NotebookMarkdownAsComments = true
+	defer func() { Options.NotebookMarkdownAsComments = false }()
+
+	result := ScanFile("test-files/notebook/analysis.ipynb")
+
+	// Assert
+	assert.Equal(t, 3, result.CodeLineCount)
+	assert.Equal(t, 4, result.CommentsLineCount)
+	assert.Equal(t, 1, result.BlankLineCount)
+}
+
+func Test_scanner_ScanFile_jupyter_notebook_with_image_output(t *testing.T) {
+	content, _ := os.ReadFile("test-files/notebook/plot.ipynb")
+	result := ScanFile("test-files/notebook/plot.ipynb")
+
+	// Assert
+	assert.Equal(t, CategoryMinified, ClassifyContent(content))
+	assert.Equal(t, "", result.Category)
+	assert.Equal(t, 3, result.CodeLineCount)
+	assert.Equal(t, 1, result.CommentsLineCount)
+}
+
+func Test_scanner_ScanFile_vue_component(t *testing.T) {
+	result := ScanFile("test-files/components/component.vue")
+
+	// Assert
+	assert.Equal(t, "Vue", result.LanguageName)
+	assert.Equal(t, 9, result.CodeLineCount)
+	assert.Equal(t, 5, result.CommentsLineCount)
+	assert.Equal(t, 3, result.BlankLineCount)
+	assert.Equal(t, map[string]int{"HTML": 3, "TypeScript": 3, "Scss": 3}, result.LanguageToCodeLineCount)
+}
+
+func Test_scanner_ScanFile_astro_component(t *testing.T) {
+	result := ScanFile("test-files/components/page.astro")
+
+	// Assert
+	assert.Equal(t, "Astro", result.LanguageName)
+	assert.Equal(t, 7, result.CodeLineCount)
+	assert.Equal(t, 2, result.CommentsLineCount)
+	assert.Equal(t, 1, result.BlankLineCount)
+	assert.Equal(t, map[string]int{"TypeScript": 3, "HTML": 1, "CSS": 3}, result.LanguageToCodeLineCount)
+}
+
 func Test_scanner_AnalyzeLine_hard(t *testing.T) {
 	testStr := "/* GFLOPS 3.398 x 20 = 67.956 */ {{7, 7}, {{1, 128, 46, 46}}, 128, 1, {1, 1}, {1, 1}, {3, 3}, {0, 0}, \"\", true, 3397788160.},"
 	_, languageInfo, _ := LookupByExtension(".cpp")
-	result, _ := AnalyzeLine(testStr, languageInfo, false)
+	result, _ := AnalyzeLine(testStr, languageInfo, LineState{})
+
+	// Assert
+	assert.Equal(t, Code, result)
+
+}
+
+func Test_scanner_AnalyzeLine_comment_starts_after_code(t *testing.T) {
+	_, languageInfo, _ := LookupByExtension(".c")
+
+	result, state := AnalyzeLine("int a; /* start of a comment", languageInfo, LineState{})
 
 	// Assert
 	assert.Equal(t, Code, result)
+	assert.Equal(t, LineState{BlockCommentEnd: "*/", BlockCommentDepth: 1}, state)
 
+	result, state = AnalyzeLine("end of the comment */", languageInfo, state)
+	assert.Equal(t, Comment, result)
+	assert.Equal(t, LineState{}, state)
 }
 
 func Test_scanner_ScanFile_binary(t *testing.T) {
@@ -81,6 +277,293 @@ func Test_scanner_ScanFile_binary(t *testing.T) {
 	assert.Equal(t, 0, result.CodeLineCount)
 
 }
+func Test_scanner_ScanFile_binary_with_supported_suffix(t *testing.T) {
+	result := ScanFile("test-files/misc/compiled.cls")
+
+	// Assert
+	assert.Equal(t, SkipReasonBinary, result.SkipReason)
+	assert.Equal(t, 0, result.CodeLineCount)
+}
+
+func Test_scanner_IsBinary(t *testing.T) {
+	// Assert
+	assert.Equal(t, false, IsBinary([]byte{}))
+	assert.Equal(t, false, IsBinary([]byte("int main() {\n\treturn 0;\n}\n")))
+	// Windows-1252 text is not valid UTF-8 but is still text
+	assert.Equal(t, false, IsBinary([]byte("// caf\xe9 cr\xe8me br\xfbl\xe9e\nint x = 1;\n")))
+	// a multi-byte character cut off at the end of the block
+	assert.Equal(t, false, IsBinary([]byte("// \xe2\x82")))
+	assert.Equal(t, true, IsBinary([]byte("text\x00with a NUL byte")))
+	assert.Equal(t, true, IsBinary([]byte{0xff, 0xfe, 0xfd, 0x01, 0x02, 0x03, 'a', 'b'}))
+}
+
+func Test_scanner_ScanFile_utf16le_with_bom(t *testing.T) {
+	result := ScanFile("test-files/encoding/utf16le.cs")
+
+	// Assert
+	assert.Equal(t, "C#", result.LanguageName)
+	assert.Equal(t, 5, result.CodeLineCount)
+	assert.Equal(t, 3, result.CommentsLineCount)
+	assert.Equal(t, 2, result.BlankLineCount)
+}
+
+func Test_scanner_ScanFile_utf32be_with_bom(t *testing.T) {
+	result := ScanFile("test-files/encoding/utf32be.cs")
+
+	// Assert
+	assert.Equal(t, "C#", result.LanguageName)
+	assert.Equal(t, 5, result.CodeLineCount)
+	assert.Equal(t, 3, result.CommentsLineCount)
+	assert.Equal(t, 2, result.BlankLineCount)
+}
+
+func Test_scanner_ScanFile_ebcdic_without_fallback_encoding(t *testing.T) {
+	result := ScanFile("test-files/encoding/ebcdic.cbl")
+
+	// Assert
+	assert.Equal(t, SkipReasonBinary, result.SkipReason)
+}
+
+func Test_scanner_ScanFile_ebcdic_fallback_encoding(t *testing.T) {
+	Options.FallbackEncoding = EncodingEBCDIC
+	defer func() { Options.FallbackEncoding = "" }()
+
+	result := ScanFile("test-files/encoding/ebcdic.cbl")
+
+	// Assert
+	assert.Equal(t, "COBOL", result.LanguageName)
+	assert.Equal(t, 5, result.CodeLineCount)
+	assert.Equal(t, 2, result.CommentsLineCount)
+	assert.Equal(t, 2, result.BlankLineCount)
+}
+
+func Test_scanner_ScanFile_cobol_fixed_format(t *testing.T) {
+	result := ScanFile("test-files/cobol/fixed-format.cbl")
+
+	// Assert
+	assert.Equal(t, "COBOL", result.LanguageName)
+	assert.Equal(t, 9, result.CodeLineCount)
+	assert.Equal(t, 5, result.CommentsLineCount)
+	assert.Equal(t, 3, result.BlankLineCount)
+}
+
+func Test_scanner_ScanFile_cobol_free_format(t *testing.T) {
+	result := ScanFile("test-files/cobol/free-format.cob")
+
+	// Assert
+	assert.Equal(t, 6, result.CodeLineCount)
+	assert.Equal(t, 2, result.CommentsLineCount)
+	assert.Equal(t, 2, result.BlankLineCount)
+}
+
+func Test_scanner_ScanFile_cobol_set_sourceformat_free(t *testing.T) {
+	result := ScanFile("test-files/cobol/set-free.cbl")
+
+	// Assert
+	assert.Equal(t, 2, result.CodeLineCount)
+	assert.Equal(t, 1, result.CommentsLineCount)
+	assert.Equal(t, 1, result.BlankLineCount)
+}
+
+func Test_scanner_scanLines_cobol_directive_after_sequence_numbers(t *testing.T) {
+	content := "000100 >>SOURCE FORMAT IS FREE\n*> A COMMENT IN COLUMN 1\nSTOP RUN."
+	codeLineCount, commentsLineCount, blankLineCount, _ := scanLines(strings.NewReader(content), Languages["COBOL"])
+
+	// Assert
+	assert.Equal(t, 2, codeLineCount)
+	assert.Equal(t, 1, commentsLineCount)
+	assert.Equal(t, 0, blankLineCount)
+}
+
+func Test_scanner_applyColumnRules(t *testing.T) {
+	languageInfo := Languages["COBOL"]
+	text, isComment := applyColumnRules(nil, []byte("000100*  COMMENT"), languageInfo)
+	assert.Equal(t, "  COMMENT", string(text))
+	assert.True(t, isComment)
+
+	// columns count characters, the identification area starts at column 73 even after multi-byte characters
+	text, isComment = applyColumnRules(nil, []byte("000200 DISPLAY 'é'."+strings.Repeat(" ", 53)+"IDENT"), languageInfo)
+	assert.Equal(t, "DISPLAY 'é'."+strings.Repeat(" ", 53), string(text))
+	assert.False(t, isComment)
+
+	// lines shorter than the indicator column only hold a sequence number
+	text, isComment = applyColumnRules(nil, []byte("0003"), languageInfo)
+	assert.Equal(t, "", string(text))
+	assert.False(t, isComment)
+}
+
+func Test_scanner_ScanFile_rpg_fixed_format(t *testing.T) {
+	result := ScanFile("test-files/mainframe/orders.rpgle")
+
+	// Assert
+	assert.Equal(t, "RPG", result.LanguageName)
+	assert.Equal(t, 7, result.CodeLineCount)
+	assert.Equal(t, 3, result.CommentsLineCount)
+	assert.Equal(t, 2, result.BlankLineCount)
+}
+
+func Test_scanner_ScanFile_rpg_free_format(t *testing.T) {
+	result := ScanFile("test-files/mainframe/lookup.sqlrpgle")
+
+	// Assert
+	assert.Equal(t, "RPG", result.LanguageName)
+	assert.Equal(t, 4, result.CodeLineCount)
+	assert.Equal(t, 1, result.CommentsLineCount)
+	assert.Equal(t, 2, result.BlankLineCount)
+}
+
+func Test_scanner_ScanFile_jcl(t *testing.T) {
+	result := ScanFile("test-files/mainframe/payroll.jcl")
+
+	// Assert
+	assert.Equal(t, "JCL", result.LanguageName)
+	assert.Equal(t, 16, result.CodeLineCount)
+	assert.Equal(t, 2, result.CommentsLineCount)
+	assert.Equal(t, 2, result.BlankLineCount)
+}
+
+func Test_scanner_DecodeText_windows1252_fallback_encoding(t *testing.T) {
+	reader, encoding := DecodeText(bufio.NewReader(strings.NewReader("// caf\xe9 \x80 5\n")), EncodingWindows1252)
+	text, _ := io.ReadAll(reader)
+
+	// Assert
+	assert.Equal(t, EncodingWindows1252, encoding)
+	assert.Equal(t, "// café € 5\n", string(text))
+}
+
+func Test_scanner_DecodeText_utf8_bom(t *testing.T) {
+	reader, encoding := DecodeText(bufio.NewReader(strings.NewReader("\xef\xbb\xbf// café\n")), EncodingWindows1252)
+	text, _ := io.ReadAll(reader)
+
+	// Assert
+	assert.Equal(t, EncodingUTF8, encoding)
+	assert.Equal(t, "// café\n", string(text))
+}
+
+func Test_scanner_LookupFallbackEncoding(t *testing.T) {
+	encoding, found := LookupFallbackEncoding("CP1252")
+	_, foundUnknown := LookupFallbackEncoding("klingon")
+
+	// Assert
+	assert.Equal(t, true, found)
+	assert.Equal(t, EncodingWindows1252, encoding)
+	assert.Equal(t, false, foundUnknown)
+}
+
+func Test_scanner_ScanFile_minified(t *testing.T) {
+	result := ScanFile("test-files/misc/minified.js")
+
+	// Assert
+	assert.Equal(t, CategoryMinified, result.Category)
+	assert.Equal(t, 1, result.CodeLineCount)
+}
+
+func Test_scanner_ScanFile_generated(t *testing.T) {
+	result := ScanFile("test-files/misc/generated.ts")
+
+	// Assert
+	assert.Equal(t, CategoryGenerated, result.Category)
+	assert.Equal(t, 8, result.CodeLineCount)
+}
+
+func Test_scanner_ScanFile_source_code_has_no_category(t *testing.T) {
+	result := ScanFile("test-files/js/easy.js")
+
+	// Assert
+	assert.Equal(t, "", result.Category)
+}
+
+func Test_scanner_ClassifyContent(t *testing.T) {
+	// Assert
+	assert.Equal(t, CategoryGenerated, ClassifyContent([]byte("// <auto-generated>\n//     This code was generated by a tool.\n// </auto-generated>\nclass A {}\n")))
+	assert.Equal(t, CategoryGenerated, ClassifyContent([]byte("package a;\n\n@Generated(\"by-hand\")\npublic class A {}\n")))
+	assert.Equal(t, CategoryGenerated, ClassifyContent([]byte("package a;\n\nimport b;\n\n@jakarta.annotation.Generated(value = \"openapi\")\n@SuppressWarnings(\"all\")\npublic final class A {}\n")))
+	assert.Equal(t, CategoryGenerated, ClassifyContent([]byte("@javax.annotation.processing.Generated(\"dagger\") public class A {}\n")))
+	assert.Equal(t, CategoryGenerated, ClassifyContent([]byte("/**\n * @generated\n */\n")))
+	assert.Equal(t, "", ClassifyContent([]byte("package a;\n\n@Entity\npublic class A {\n    @Generated\n    @Override\n    public boolean equals(Object o) { return true; }\n}\n")))
+	assert.Equal(t, "", ClassifyContent([]byte("fmt.Println(\"// Code generated by x. DO NOT EDIT.\")\n")))
+	assert.Equal(t, CategoryMinified, ClassifyContent([]byte("/*! v1.0 */\n"+strings.Repeat("a.b=function(c){return c+1};", 20)+"\n")))
+	assert.Equal(t, "", ClassifyContent([]byte("")))
+}
+
+func Test_scanner_ScanFile_content_hash(t *testing.T) {
+	Options.HashContent = true
+	defer func() { Options.HashContent = false }()
+
+	copy1 := ScanFile("test-files/duplicates/service-a/client.js")
+	copy2 := ScanFile("test-files/duplicates/service-b/client.js")
+	other := ScanFile("test-files/duplicates/service-b/index.js")
+
+	// Assert
+	assert.Equal(t, 64, len(copy1.ContentHash))
+	assert.Equal(t, copy1.ContentHash, copy2.ContentHash)
+	assert.NotEqual(t, copy1.ContentHash, other.ContentHash)
+	assert.Equal(t, 4, copy1.CodeLineCount)
+}
+
+func Test_scanner_ScanFile_no_content_hash_by_default(t *testing.T) {
+	result := ScanFile("test-files/duplicates/service-a/client.js")
+
+	// Assert
+	assert.Equal(t, "", result.ContentHash)
+}
+
+func Test_scanner_ScanFile_cr_line_endings(t *testing.T) {
+	result := ScanFile("test-files/line-endings/cr-only.c")
+
+	// Assert
+	assert.Equal(t, LineEndingCR, result.LineEnding)
+	assert.Equal(t, 3, result.CodeLineCount)
+	assert.Equal(t, 1, result.CommentsLineCount)
+	assert.Equal(t, 2, result.BlankLineCount)
+}
+
+func Test_scanner_ScanFile_mixed_line_endings(t *testing.T) {
+	result := ScanFile("test-files/line-endings/mixed.c")
+
+	// Assert
+	assert.Equal(t, LineEndingMixed, result.LineEnding)
+	assert.Equal(t, 3, result.CodeLineCount)
+	assert.Equal(t, 1, result.CommentsLineCount)
+	assert.Equal(t, 2, result.BlankLineCount)
+}
+
+func Test_scanner_ScanFile_lf_line_endings(t *testing.T) {
+	result := ScanFile("test-files/c/mid-line.c")
+
+	// Assert
+	assert.Equal(t, LineEndingLF, result.LineEnding)
+}
+
+func Test_scanner_lineReader(t *testing.T) {
+	reader := newLineReader(strings.NewReader("a\r\nb\rc\n\rd"))
+	lines := []string{}
+	for {
+		line, err := reader.readLine(lineChunkSize)
+		lines = append(lines, line)
+		if err != nil {
+			break
+		}
+	}
+
+	// Assert
+	assert.Equal(t, []string{"a", "b", "c", "", "d"}, lines)
+	assert.Equal(t, LineEndingMixed, reader.lineEnding())
+	assert.Equal(t, "", newLineReader(strings.NewReader("single line")).lineEnding())
+}
+
+func Test_scanner_lineReader_crlf_split_across_reads(t *testing.T) {
+	reader := newLineReader(io.MultiReader(strings.NewReader("a\r"), strings.NewReader("\nb")))
+	first, _ := reader.readLine(lineChunkSize)
+	second, err := reader.readLine(lineChunkSize)
+
+	// Assert
+	assert.Equal(t, "a", first)
+	assert.Equal(t, "b", second)
+	assert.Equal(t, io.EOF, err)
+	assert.Equal(t, LineEndingCRLF, reader.lineEnding())
+}
+
 func Test_scanner_ScanFile_blank_file(t *testing.T) {
 	result := ScanFile("test-files/misc/blank-file.js")
 
@@ -103,6 +586,83 @@ func Test_scanner_ScanFile_massive_line_yaml(t *testing.T) {
 	// Assert
 	assert.Equal(t, 1, result.CodeLineCount)
 }
+func Test_scanner_scanLines_token_split_across_chunks(t *testing.T) {
+	_, languageInfo, _ := LookupByExtension(".c")
+	// the */ ending the comment straddles the end of the first chunk
+	line := "/*" + strings.Repeat("c", lineChunkSize-3) + "*/ int x;"
+	codeLineCount, commentsLineCount, blankLineCount, _ := scanLines(strings.NewReader(line+"\nint y;"), languageInfo)
+
+	// Assert
+	assert.Equal(t, 2, codeLineCount)
+	assert.Equal(t, 0, commentsLineCount)
+	assert.Equal(t, 0, blankLineCount)
+}
+
+func Test_scanner_scanLines_huge_lines(t *testing.T) {
+	_, languageInfo, _ := LookupByExtension(".c")
+	huge := strings.Repeat("x = y; ", 3*lineChunkSize/7)
+	lines := []string{
+		"// " + huge,
+		"/* " + huge + " */",
+		"char *s = \"" + strings.Repeat("/* // ", lineChunkSize/2) + "\";",
+		"   " + strings.Repeat(" ", 2*lineChunkSize),
+		"/*",
+		huge,
+		"*/",
+		"int z;",
+	}
+	codeLineCount, commentsLineCount, blankLineCount, _ := scanLines(strings.NewReader(strings.Join(lines, "\n")), languageInfo)
+
+	// Assert
+	assert.Equal(t, 2, codeLineCount)
+	assert.Equal(t, 5, commentsLineCount)
+	assert.Equal(t, 1, blankLineCount)
+}
+
+func Test_scanner_scanLines_does_not_allocate_per_line(t *testing.T) {
+	_, languageInfo, _ := LookupByExtension(".c")
+	fewLines := strings.Repeat("int x; /* a \"comment\" */\r\n", 10)
+	manyLines := strings.Repeat("int x; /* a \"comment\" */\r\n", 10000)
+	allocations := func(content string) float64 {
+		return testing.AllocsPerRun(10, func() {
+			scanLines(strings.NewReader(content), languageInfo)
+		})
+	}
+
+	// Assert
+	// the pool of line readers can drop its items, ex: under the race detector, so the counts may differ slightly
+	assert.InDelta(t, allocations(fewLines), allocations(manyLines), 5)
+}
+
+func Test_scanner_lineReader_readLine_truncates_long_lines(t *testing.T) {
+	reader := newLineReader(strings.NewReader(strings.Repeat("a", 100) + "\nb"))
+	first, _ := reader.readLine(10)
+	second, err := reader.readLine(10)
+
+	// Assert
+	assert.Equal(t, strings.Repeat("a", 10), first)
+	assert.Equal(t, "b", second)
+	assert.Equal(t, io.EOF, err)
+}
+
+func Test_scanner_ScanFile_notebook_larger_than_memory_limit(t *testing.T) {
+	Options.FileMemoryLimit = 100
+	defer func() { Options.FileMemoryLimit = DefaultFileMemoryLimit }()
+
+	result := ScanFile("test-files/notebook/analysis.ipynb")
+
+	// Assert
+	assert.Equal(t, SkipReasonTooLarge, result.SkipReason)
+}
+
+func Test_scanner_ScanFile_invalid_notebook(t *testing.T) {
+	result := ScanFile("test-files/notebook/truncated.ipynb")
+
+	// Assert
+	assert.Equal(t, SkipReasonInvalidNotebook, result.SkipReason)
+	assert.Equal(t, 0, result.CodeLineCount)
+}
+
 func Test_scanner_ScanFile_minified_line_txt(t *testing.T) {
 	result := ScanFile("test-files/misc/minified.js")
 
@@ -124,6 +684,65 @@ func Test_scanner_ScanFile_dockerfile_no_suffix(t *testing.T) {
 	// Assert
 	assert.Equal(t, 2, result.CodeLineCount)
 }
+func Test_scanner_ScanFile_shebang_no_suffix(t *testing.T) {
+	result := ScanFile("test-files/shebang/run-python")
+
+	// Assert
+	assert.Equal(t, "Python", result.LanguageName)
+	assert.Equal(t, 1, result.CodeLineCount)
+	assert.Equal(t, 2, result.CommentsLineCount)
+}
+
+func Test_scanner_ParseShebangInterpreter(t *testing.T) {
+	// Assert
+	assert.Equal(t, "bash", ParseShebangInterpreter("#!/bin/bash"))
+	assert.Equal(t, "python3", ParseShebangInterpreter("#!/usr/bin/env python3"))
+	assert.Equal(t, "node", ParseShebangInterpreter("#!/usr/bin/env -S NODE_ENV=production node --harmony"))
+	assert.Equal(t, "", ParseShebangInterpreter("# just a comment"))
+	assert.Equal(t, "", ParseShebangInterpreter("#!"))
+}
+
+func Test_scanner_LookupByInterpreter(t *testing.T) {
+	language, _, found := LookupByInterpreter("python3.11")
+
+	// Assert
+	assert.Equal(t, "Python", language)
+	assert.Equal(t, true, found)
+
+	_, _, found = LookupByInterpreter("unknown-interpreter")
+	assert.Equal(t, false, found)
+}
+
+func Test_scanner_LookupByExtension_is_deterministic(t *testing.T) {
+	for range 20 {
+		language, _, found := LookupByExtension(".h")
+
+		// Assert
+		assert.Equal(t, "C Header", language)
+		assert.Equal(t, true, found)
+	}
+}
+
+func Test_scanner_LookupByExtensionAndContent(t *testing.T) {
+	expectedLanguages := map[string]string{
+		"test-files/ambiguous/person.h":           "Objective-C",
+		"test-files/ambiguous/circle.h":           "C++ Header",
+		"test-files/ambiguous/point.h":            "C Header",
+		"test-files/ambiguous/area.m":             "MATLAB",
+		"test-files/ambiguous/config.inc":         "PHP",
+		"test-files/ambiguous/greet.inc":          "Pascal",
+		"test-files/ambiguous/report.cls":         "LaTeX",
+		"test-files/ambiguous/AccountService.cls": "Apex",
+	}
+	for filePath, expectedLanguage := range expectedLanguages {
+		language, _, found := LookupByExtensionAndContent(ParseFileSuffix(filePath), filePath)
+
+		// Assert
+		assert.Equal(t, expectedLanguage, language, filePath)
+		assert.Equal(t, true, found)
+	}
+}
+
 func Test_scanner_ParseFileSuffix(t *testing.T) {
 	suffix := ParseFileSuffix("main.js")
 
@@ -160,6 +779,114 @@ func Test_scanner_WalkDirectory_containing_with_files_without_suffix(t *testing.
 	assert.Equal(t, 2, len(result))
 }
 
+func Test_scanner_WalkDirectory_containing_scripts_without_suffix(t *testing.T) {
+	ignorePatterns := []string{}
+
+	result := WalkDirectory("test-files/shebang", ignorePatterns)
+
+	// Assert
+	assert.Equal(t, 2, len(result))
+}
+
+func Test_scanner_IsInDotDirectory(t *testing.T) {
+	// Assert
+	assert.Equal(t, true, IsInDotDirectory(".git/objects/3f/2a9c"))
+	assert.Equal(t, true, IsInDotDirectory("tools/.cache/run"))
+	assert.Equal(t, false, IsInDotDirectory("bin/run"))
+	assert.Equal(t, false, IsInDotDirectory(".run"))
+	assert.Equal(t, false, IsInDotDirectory("../scripts/run"))
+}
+
+func Test_scanner_LookupByShebang_without_shebang(t *testing.T) {
+	_, _, found := LookupByShebang("test-files/shebang/notes")
+
+	// Assert
+	assert.Equal(t, false, found)
+}
+
+func Test_scanner_WalkDirectory_vendored_directories(t *testing.T) {
+	result := WalkDirectory("test-files/vendored", []string{})
+
+	// Assert
+	assert.Equal(t, 3, len(result))
+	categories := map[string]string{}
+	for _, file := range result {
+		categories[filepath.Base(file.FilePath)] = file.Category
+	}
+	assert.Equal(t, "", categories["app.js"])
+	assert.Equal(t, CategoryVendored, categories["index.js"])
+	assert.Equal(t, CategoryVendored, categories["sha1.c"])
+}
+
+func Test_scanner_WalkDirectory_inside_vendored_directory(t *testing.T) {
+	result := WalkDirectory("test-files/vendored/node_modules", []string{})
+
+	// Assert
+	assert.Equal(t, 1, len(result))
+	assert.Equal(t, "", result[0].Category)
+}
+
+func Test_scanner_LoadVendoredDirectories(t *testing.T) {
+	defaultVendoredDirectories := VendoredDirectories
+	defer func() { VendoredDirectories = defaultVendoredDirectories }()
+
+	LoadVendoredDirectories("test-files/test-vendored-file.txt")
+
+	// Assert
+	assert.Equal(t, []string{"node_modules"}, VendoredDirectories)
+	assert.Equal(t, true, IsVendoredPath("web/node_modules/react/index.js"))
+	assert.Equal(t, false, IsVendoredPath("src/third_party/sha1.c"))
+}
+
+func Test_scanner_IsVendoredPath(t *testing.T) {
+	// Assert
+	assert.Equal(t, true, IsVendoredPath("node_modules/left-pad/index.js"))
+	assert.Equal(t, true, IsVendoredPath("ios/Pods/Alamofire/Source/Session.swift"))
+	assert.Equal(t, true, IsVendoredPath(".venv/lib/python3.12/site-packages/six.py"))
+	assert.Equal(t, false, IsVendoredPath("src/vendors.js"))
+	assert.Equal(t, false, IsVendoredPath("vendor"))
+}
+
+func Test_scanner_WalkDirectory_test_code(t *testing.T) {
+	result := WalkDirectory("test-files/testcode", []string{})
+
+	// Assert
+	assert.Equal(t, 4, len(result))
+	isTest := map[string]bool{}
+	for _, file := range result {
+		isTest[filepath.Base(file.FilePath)] = file.IsTest
+	}
+	assert.Equal(t, false, isTest["App.java"])
+	assert.Equal(t, true, isTest["AppTest.java"])
+	assert.Equal(t, false, isTest["AppIT.java"])
+	assert.Equal(t, true, isTest["app.js"])
+}
+
+func Test_scanner_LoadTestPatterns(t *testing.T) {
+	defer func() { testPatterns = []*regexp.Regexp{} }()
+
+	LoadTestPatterns("test-files/test-test-patterns.txt")
+
+	// Assert
+	assert.Equal(t, true, IsTestPath("src/it/java/AppIT.java"))
+	assert.Equal(t, false, IsTestPath("src/main/java/App.java"))
+}
+
+func Test_scanner_IsTestPath(t *testing.T) {
+	// Assert
+	assert.Equal(t, true, IsTestPath("src/test/java/com/example/AppTest.java"))
+	assert.Equal(t, true, IsTestPath("app/src/androidTest/java/MainActivityTest.kt"))
+	assert.Equal(t, true, IsTestPath("src/components/__tests__/Button.jsx"))
+	assert.Equal(t, true, IsTestPath("scanner/scanner_test.go"))
+	assert.Equal(t, true, IsTestPath("src/app/app.component.spec.ts"))
+	assert.Equal(t, true, IsTestPath("pkg/test_models.py"))
+	assert.Equal(t, true, IsTestPath("spec/models/user_spec.rb"))
+	assert.Equal(t, true, IsTestPath("Services.Tests/OrderServiceTests.cs"))
+	assert.Equal(t, false, IsTestPath("src/main/java/com/example/App.java"))
+	assert.Equal(t, false, IsTestPath("src/testing.py"))
+	assert.Equal(t, false, IsTestPath("src/contest/latest.go"))
+}
+
 func Test_scanner_ReadIgnoreFile(t *testing.T) {
 
 	result := ReadIgnoreFile("test-files/test-ignore-file.txt")
@@ -180,3 +907,324 @@ func Test_scanner_LoadLanguages(t *testing.T) {
 	assert.Equal(t, "YAML", language)
 	assert.Equal(t, true, found)
 }
+
+func Test_scanner_ValidateLanguages_invalid_config(t *testing.T) {
+	content, _ := os.ReadFile("test-files/config/invalid-languages.json")
+	problems := []string{}
+	for _, problem := range ValidateLanguages(content, Languages) {
+		problems = append(problems, problem.String())
+	}
+
+	// Assert
+	assert.Equal(t, []string{
+		`4:18: YAML.Extension: unknown key "Extension" is ignored, did you mean "Extensions"?`,
+		`5:20: YAML.Extensions[0]: extension "yaml3" must start with a dot, ex: ".yaml3"`,
+		`8:22: My Script.LineComments[0]: must not be empty`,
+		`9:27: My Script.MultiLineComments[0]: must be a pair of start and end tokens, ex: ["/*", "*/"]`,
+		`10:24: My Script.IgnoredColumns[0]: must be a range of columns [first, last] starting at column 1, ex: [1, 6]`,
+		"11:23: My Script.BlankPatterns[0]: invalid regular expression, error parsing regexp: missing closing ]: `[unclosed`",
+		`12:20: My Script.Extensions[0]: extension ".as" is claimed by both ActionScript and My Script, only ActionScript is used. Set a higher Priority to choose the language`,
+		`13:19: My Script.FileNames[0]: "Dockerfile" is also claimed by Docker, which of them is used is random`,
+		`15:12: Empty: claims no extensions, file names or interpreters, so no file is counted as Empty`,
+		`17:19: Broken.Extensions: must be a []string, not a JSON string`,
+	}, problems)
+}
+
+func Test_scanner_ValidateLanguages_duplicate_definitions(t *testing.T) {
+	content := "{\n  \"My YAML\": {\"Extensions\": [\".yaml\"], \"Priority\": 1},\n  \"My YAML\": {\"Extensions\": [\".yaml\"]}\n}"
+	problems := ValidateLanguages([]byte(content), Languages)
+
+	// Assert
+	assert.Equal(t, 2, len(problems))
+	assert.Equal(t, "3:3: My YAML: is defined more than once, only the last definition is used", problems[0].String())
+	assert.Equal(t, "My YAML.Extensions[0]", problems[1].Path)
+	assert.Equal(t, 3, problems[1].Line)
+}
+
+func Test_scanner_ValidateLanguages_invalid_json(t *testing.T) {
+	problems := ValidateLanguages([]byte("{\n  \"YAML\": {\"Extensions\": [\".yaml\"]},\n}"), Languages)
+
+	// Assert
+	assert.Equal(t, 1, len(problems))
+	assert.Equal(t, 3, problems[0].Line)
+	assert.Equal(t, "", problems[0].Path)
+}
+
+func Test_scanner_ValidateLanguages_default_languages(t *testing.T) {
+	content, _ := json.Marshal(Languages)
+
+	// Assert
+	assert.Equal(t, []ConfigProblem{}, ValidateLanguages(content, map[string]LanguageInfo{}))
+}
+
+func Test_scanner_ScanFiles_matches_sequential_scan(t *testing.T) {
+	files := WalkDirectory("test-files", []string{})
+	expected := []FileScanResults{}
+	for _, file := range files {
+		expected = append(expected, scanFoundFile(file))
+	}
+
+	filesChannel := make(chan FileToScan)
+	go StreamDirectory("test-files", []string{}, filesChannel)
+	result := ScanFiles(filesChannel, 4)
+
+	// Assert
+	assert.Equal(t, expected, result)
+}
+
+func Test_scanner_AnalyzeLine_comment_tokens_inside_strings(t *testing.T) {
+	_, languageInfo, _ := LookupByExtension(".c")
+
+	// Assert
+	result, state := AnalyzeLine(`"// not a comment";`, languageInfo, LineState{})
+	assert.Equal(t, Code, result)
+	assert.Equal(t, LineState{}, state)
+
+	result, state = AnalyzeLine(`"\"/*" "*/";`, languageInfo, LineState{})
+	assert.Equal(t, Code, result)
+	assert.Equal(t, LineState{}, state)
+
+	result, state = AnalyzeLine(`'"' /* comment with a " quote */`, languageInfo, LineState{})
+	assert.Equal(t, Code, result)
+	assert.Equal(t, LineState{}, state)
+
+	// a string continued on the next line with an escaped line break
+	result, state = AnalyzeLine(`char *s = "continued \`, languageInfo, LineState{})
+	assert.Equal(t, Code, result)
+	assert.Equal(t, "\"", state.StringDelimiter)
+	result, state = AnalyzeLine(`// still inside the string";`, languageInfo, state)
+	assert.Equal(t, Code, result)
+	assert.Equal(t, LineState{}, state)
+}
+
+// reports the throughput of scanning a file, run with go test -bench . -benchmem ./scanner
+func benchmarkScanFile(b *testing.B, filePath string) {
+	info, err := os.Stat(filePath)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.SetBytes(info.Size())
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		ScanFile(filePath)
+	}
+}
+
+func Benchmark_scanner_ScanFile_c_evil(b *testing.B) {
+	benchmarkScanFile(b, "test-files/c/evil.c")
+}
+
+func Benchmark_scanner_ScanFile_cpp_evil(b *testing.B) {
+	benchmarkScanFile(b, "test-files/cpp/evil.cpp")
+}
+
+func Benchmark_scanner_ScanFile_massive_line_yaml(b *testing.B) {
+	benchmarkScanFile(b, "test-files/misc/massive-line.yaml")
+}
+
+func Test_scanner_ScanFile_js_template_literal(t *testing.T) {
+	result := ScanFile("test-files/strings/template-literal.js")
+
+	// Assert
+	assert.Equal(t, 7, result.CodeLineCount)
+	assert.Equal(t, 2, result.CommentsLineCount)
+	assert.Equal(t, 2, result.BlankLineCount)
+}
+
+func Test_scanner_ScanFile_js_regex_literal(t *testing.T) {
+	result := ScanFile("test-files/strings/regex-literal.js")
+
+	// Assert
+	assert.Equal(t, 6, result.CodeLineCount)
+	assert.Equal(t, 3, result.CommentsLineCount)
+	assert.Equal(t, 2, result.BlankLineCount)
+}
+
+func Test_scanner_ScanFile_cpp_raw_string(t *testing.T) {
+	result := ScanFile("test-files/strings/raw-string.cpp")
+
+	// Assert
+	assert.Equal(t, 10, result.CodeLineCount)
+	assert.Equal(t, 2, result.CommentsLineCount)
+	assert.Equal(t, 3, result.BlankLineCount)
+}
+
+func Test_scanner_ScanFile_cpp_digit_separator(t *testing.T) {
+	result := ScanFile("test-files/cpp/digit-separator.cpp")
+
+	// Assert
+	assert.Equal(t, 5, result.CodeLineCount)
+	assert.Equal(t, 3, result.CommentsLineCount)
+	assert.Equal(t, 1, result.BlankLineCount)
+}
+
+func Test_scanner_ScanFile_php_heredoc(t *testing.T) {
+	result := ScanFile("test-files/strings/heredoc.php")
+
+	// Assert
+	assert.Equal(t, 8, result.CodeLineCount)
+	assert.Equal(t, 2, result.CommentsLineCount)
+	assert.Equal(t, 1, result.BlankLineCount)
+}
+
+func Test_scanner_ScanFile_c_disabled_code(t *testing.T) {
+	result := ScanFile("test-files/preprocessor/disabled.c")
+
+	// Assert
+	assert.Equal(t, 27, result.CodeLineCount)
+	assert.Equal(t, 1, result.CommentsLineCount)
+	assert.Equal(t, 7, result.BlankLineCount)
+}
+
+func Test_scanner_ScanFile_c_disabled_code_as_comments(t *testing.T) {
+	Options.DisabledCodeAsComments = true
+	defer func() { Options.DisabledCodeAsComments = false }()
+
+	result := ScanFile("test-files/preprocessor/disabled.c")
+
+	// Assert
+	assert.Equal(t, 18, result.CodeLineCount)
+	assert.Equal(t, 10, result.CommentsLineCount)
+	assert.Equal(t, 7, result.BlankLineCount)
+}
+
+func Test_scanner_conditionals_update(t *testing.T) {
+	tests := []struct {
+		lines    []string
+		disabled bool
+	}{
+		{[]string{"#if 0"}, true},
+		{[]string{"#if\t0"}, true},
+		{[]string{"# if (0) /* disabled */"}, true},
+		{[]string{"#if false"}, true},
+		{[]string{"#if 0", "#else"}, false},
+		{[]string{"#if 1", "#else"}, true},
+		{[]string{"#if 1", "#elif 1"}, true},
+		{[]string{"#if 0", "#elif 1"}, false},
+		{[]string{"#if 0", "#if 1"}, true},
+		{[]string{"#if 0", "#if 1", "#endif"}, true},
+		{[]string{"#if 0", "#endif"}, false},
+		{[]string{"#ifdef DEBUG", "#else"}, false},
+		{[]string{"#if VERSION > 2"}, false},
+		{[]string{"#endif"}, false},
+		{[]string{"#include <stdio.h>"}, false},
+	}
+	for _, test := range tests {
+		var c conditionals
+		for _, line := range test.lines {
+			c.update([]byte(line))
+		}
+
+		// Assert
+		assert.Equal(t, test.disabled, c.disabled(), test.lines)
+	}
+}
+
+func Test_scanner_scanLines_line_comment_patterns(t *testing.T) {
+	languageInfo := LanguageInfo{LineComments: []string{"!"}, LineCommentPatterns: []string{`^[cC*]`}}
+	_, err := languageInfo.compilePatterns()
+	lines := []string{
+		"C     FIXED-FORM COMMENT",
+		"      PROGRAM MAIN",
+		"*     ANOTHER COMMENT 'UNCLOSED",
+		"      X = 1 ! trailing comment",
+		"      ! free-form comment",
+		"      CALL C(X)",
+		"      END",
+	}
+	codeLineCount, commentsLineCount, blankLineCount, _ := scanLines(strings.NewReader(strings.Join(lines, "\n")), languageInfo)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, 4, codeLineCount)
+	assert.Equal(t, 3, commentsLineCount)
+	assert.Equal(t, 0, blankLineCount)
+}
+
+func Test_scanner_scanLines_blank_and_ignore_patterns(t *testing.T) {
+	_, languageInfo, _ := LookupByExtension(".c")
+	languageInfo.BlankPatterns = []string{`^\s*[{}();]\s*$`}
+	languageInfo.IgnorePatterns = []string{`^\s*#pragma\b`}
+	_, err := languageInfo.compilePatterns()
+	lines := []string{
+		"int main()",
+		"{",
+		"#pragma once",
+		"  /*",
+		"  }",
+		"  */",
+		"  return 0;",
+		"}",
+	}
+	codeLineCount, commentsLineCount, blankLineCount, _ := scanLines(strings.NewReader(strings.Join(lines, "\n")), languageInfo)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, 2, codeLineCount)
+	assert.Equal(t, 3, commentsLineCount)
+	assert.Equal(t, 2, blankLineCount)
+}
+
+func Test_scanner_compilePatterns_invalid_pattern(t *testing.T) {
+	languageInfo := LanguageInfo{BlankPatterns: []string{`^\s*$`, `[unclosed`}}
+	pattern, err := languageInfo.compilePatterns()
+
+	// Assert
+	assert.NotNil(t, err)
+	assert.Equal(t, "[unclosed", pattern)
+	assert.Nil(t, languageInfo.patterns)
+}
+
+func Test_scanner_scanLines_php_attributes(t *testing.T) {
+	lines := []string{
+		"<?php",
+		"# a comment",
+		"#[Route(\"/orders\", methods: [\"GET\"])]",
+		"  #[",
+		"    Deprecated,",
+		"  ]",
+		"public function list() {} # a trailing comment",
+		"#a comment without a space",
+	}
+	codeLineCount, commentsLineCount, blankLineCount, _ := scanLines(strings.NewReader(strings.Join(lines, "\n")), Languages["PHP"])
+
+	// Assert
+	assert.Equal(t, 6, codeLineCount)
+	assert.Equal(t, 2, commentsLineCount)
+	assert.Equal(t, 0, blankLineCount)
+}
+
+func Test_scanner_scanLines_multi_line_strings(t *testing.T) {
+	tests := []struct {
+		suffix       string
+		content      string
+		codeLines    int
+		commentLines int
+	}{
+		{".go", "s := `\n// not a comment\n`\n// a comment", 3, 1},
+		{".go", "s := `C:\\`\n// a comment", 1, 1},
+		{".java", "String s = \"\"\"\n    /* \\\"\"\" not the end\n    \"\"\";\n// a comment", 3, 1},
+		{".cs", "var s = @\"a \"\"quoted\"\"\n// not a comment\n\";\n// a comment", 3, 1},
+		{".cs", "var s = $@\"\n// not a comment\n\"; // a comment\n// a comment", 3, 1},
+		{".kt", "val s = \"\"\"\n// not a comment\n\"\"\"\n// a comment", 3, 1},
+		{".rb", "sql = <<~SQL.strip\n  -- SQL\n  # not a comment\n  SQL\n# a comment", 4, 1},
+		{".rb", "x = y << z\n# a comment", 1, 1},
+		{".rb", "list<<item\n# a comment", 1, 1},
+		{".rb", "items<<EOS\n# a comment", 1, 1},
+		{".rb", "call(a)<<B\n# a comment", 1, 1},
+		{".rb", "puts <<EOS\n# not a comment\nEOS\n# a comment", 3, 1},
+		{".rb", "puts <<'eos'\n# not a comment\neos\n# a comment", 3, 1},
+		{".sh", "cat << EOF\n# not a comment\nEOF\n# a comment", 3, 1},
+		{".sh", "cat <<- 'EOF'\n\t# not a comment\n\tEOF\n# a comment", 3, 1},
+		{".sh", "cat <<'EOF' > out.txt # the heredoc starts on the next line\n# not a comment\nEOF\n# a comment", 3, 1},
+		{".rs", "let s = r#\"\n// \"not the end\n\"#;\n// a comment", 3, 1},
+	}
+	for _, test := range tests {
+		_, languageInfo, _ := LookupByExtension(test.suffix)
+		codeLineCount, commentsLineCount, _, _ := scanLines(strings.NewReader(test.content), languageInfo)
+
+		// Assert
+		assert.Equal(t, test.codeLines, codeLineCount, test.content)
+		assert.Equal(t, test.commentLines, commentsLineCount, test.content)
+	}
+}
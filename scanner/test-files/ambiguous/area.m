@@ -0,0 +1,4 @@
+% compute the area
+function a = area(r)
+    a = pi * r^2;
+end
@@ -0,0 +1,8 @@
+#include <vector>
+
+namespace shapes {
+class Circle {
+public:
+    double radius;
+};
+}
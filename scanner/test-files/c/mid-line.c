@@ -0,0 +1,10 @@
+int a; /* start of a long comment
+          still in the comment
+          still in the comment */
+int b; /* one */ int c; /* two */
+/* comment */ int d;
+/* one */ /* two
+   three */
+char *s = "/* not a comment"; int e;
+int f; /* unterminated */ /* and another
+end of comment */ int g;
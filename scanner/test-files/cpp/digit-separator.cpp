@@ -0,0 +1,8 @@
+// C++14 digit separators are not character literals
+int n = 1'000; /* a comment
+                  spanning lines */
+long mask = 0xFF'FF'00'00; /*
+char c = '*/ char c = 'a';
+auto b = 0b1010'1010; // binary
+switch (c) { case'a': break; }
+// done
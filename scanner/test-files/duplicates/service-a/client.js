@@ -0,0 +1,5 @@
+// generated API client copied into each service
+export async function getUser(id) {
+  const response = await fetch(`/api/users/${id}`);
+  return response.json();
+}
@@ -0,0 +1,3 @@
+import { getUser } from "./client.js";
+
+getUser(1).then(console.log);
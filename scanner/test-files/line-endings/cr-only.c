@@ -0,0 +1 @@
+/* classic Mac OS file */int main(void) {    return 0; // done}
\ No newline at end of file
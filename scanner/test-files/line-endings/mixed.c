@@ -0,0 +1,4 @@
+/* copied from several editors */
+int main(void) {
+
+    return 0; // done}
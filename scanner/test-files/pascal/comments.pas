@@ -0,0 +1,10 @@
+{ Header comment
+  spanning lines (* with a token from the other comment style *)
+}
+program Hello;
+(* A comment with a brace } inside
+   that does not end it *)
+begin
+  // single line comment
+  WriteLn('Hello { not a comment');
+end.
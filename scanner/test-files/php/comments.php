@@ -0,0 +1,7 @@
+<?php
+# shell style comment
+// c++ style comment
+/* block comment
+   # still in the block */
+$url = "http://example.com"; # trailing comment
+echo $url;
@@ -0,0 +1,34 @@
+#include <stdio.h>
+
+#if 0
+int unused(void) {
+    return 0;
+}
+#endif
+
+#if 1
+int enabled(void) { return 1; }
+#else
+int fallback(void) { return 2; }
+#endif
+
+#ifdef DEBUG
+int debug = 1;
+#elif 0
+int never = 1;
+#endif
+
+#if (false) // kept for reference
+    #if 1
+    int nested = 1;
+    #endif
+
+    int old = 2;
+#elif 1
+int current = 3;
+#endif
+
+int main(void) {
+    /* a comment */
+    return enabled();
+}
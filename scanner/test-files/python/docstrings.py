@@ -0,0 +1,23 @@
+"""Module docstring
+spanning lines
+"""
+import os
+
+sql = """SELECT *
+# not a comment
+FROM table"""
+
+
+def foo():
+    '''Function docstring using single quotes'''
+    return r"""raw string"""
+
+
+class Bar:
+    r"""Raw docstring
+
+    with a blank line
+    """
+    x = call(
+        """argument string""",
+    )
@@ -0,0 +1,10 @@
+<?php
+// heredocs keep comment tokens as text
+$html = <<<EOT
+  # not a comment
+  // not a comment
+  EOT;
+$nowdoc = <<<'EOT'
+/* not a comment */
+EOT;
+# a real comment
@@ -0,0 +1,14 @@
+#include <string>
+
+// raw strings keep comment tokens as text
+const std::string script = R"(
+// not a comment
+/* not a comment */
+)";
+
+const std::string nested = u8R"sql(
+-- )" does not end the string
+// not a comment
+)sql";
+/* a real comment */
+int x = 1;
@@ -0,0 +1,10 @@
+// strips the backticks of inline code
+const code = text.replace(/`/g, "");
+const ratio = total / count; // a division, not a regex
+
+function quote(s) {
+  return /^["'`]/.test(s) ? s : `"${s}"`;
+}
+/* the backticks above do not open template literals */
+const url = input.match(/https?:\/\/[^ ]+/);
+// done
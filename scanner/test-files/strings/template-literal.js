@@ -0,0 +1,10 @@
+// a query built with a template literal
+const query = `
+  SELECT *
+  /* not a comment */
+  // not a comment either
+  FROM users WHERE name = \`${name}\`
+`;
+
+const escaped = `a \\` + 1; // the literal ends before the comment
+/* a real comment */
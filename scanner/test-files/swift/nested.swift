@@ -0,0 +1,6 @@
+/* outer comment
+   /* nested comment */
+   still inside the outer comment
+*/
+let answer = 42
+/* a /* b */ c */ let x = 1
@@ -0,0 +1,5 @@
+public class App {
+    public static int add(int a, int b) {
+        return a + b;
+    }
+}
@@ -0,0 +1,8 @@
+import static org.junit.jupiter.api.Assertions.assertEquals;
+
+class AppTest {
+    @org.junit.jupiter.api.Test
+    void adds() {
+        assertEquals(3, App.add(1, 2));
+    }
+}
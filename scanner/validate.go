@@ -0,0 +1,378 @@
+package scanner
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-cloc/logger"
+	"os"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// ConfigProblem is a mistake found in a languages configuration, ex: an extension without a leading dot
+type ConfigProblem struct {
+	Path    string // path of the JSON value holding the mistake, ex: YAML.Extensions[2]
+	Line    int
+	Column  int
+	Message string
+}
+
+func (problem ConfigProblem) String() string {
+	if problem.Path == "" {
+		return fmt.Sprintf("%d:%d: %s", problem.Line, problem.Column, problem.Message)
+	}
+	return fmt.Sprintf("%d:%d: %s: %s", problem.Line, problem.Column, problem.Path, problem.Message)
+}
+
+// configValidator collects the problems of a languages configuration along with their offsets in its JSON content
+type configValidator struct {
+	content   []byte
+	locations map[string]int // offset of every JSON value by its path
+	problems  []ConfigProblem
+	offsets   []int
+}
+
+// ValidateLanguages checks a languages configuration in the format of --override-languages before it is loaded
+// over the languages of base, and returns its problems in the order they appear in the content:
+//   - invalid JSON and values of the wrong type
+//   - unknown keys, ex: "Extension" instead of "Extensions", which are silently ignored
+//   - extensions without a leading dot, empty tokens and malformed comment pairs, strings and columns
+//   - invalid regular expressions in the line patterns
+//   - languages claiming no extensions, file names or interpreters
+//   - extensions, file names and interpreters claimed by several languages, where only one of them can be used
+func ValidateLanguages(content []byte, base map[string]LanguageInfo) []ConfigProblem {
+	v := &configValidator{content: content, locations: map[string]int{}}
+	rawLanguages := map[string]json.RawMessage{}
+	if err := json.Unmarshal(content, &rawLanguages); err != nil {
+		var syntaxError *json.SyntaxError
+		var typeError *json.UnmarshalTypeError
+		if errors.As(err, &syntaxError) {
+			v.reportAt(int(syntaxError.Offset), "", "invalid JSON, "+syntaxError.Error())
+		} else if errors.As(err, &typeError) {
+			v.reportAt(0, "", "must be an object of languages by name")
+		} else {
+			v.reportAt(0, "", err.Error())
+		}
+		return v.sortedProblems()
+	}
+	v.locate(json.NewDecoder(bytes.NewReader(content)), "")
+
+	languages := map[string]LanguageInfo{}
+	for langName, languageInfo := range base {
+		languages[langName] = languageInfo
+	}
+	definedLanguages := []string{}
+	for langName, raw := range rawLanguages {
+		definedLanguages = append(definedLanguages, langName)
+		languages[langName] = v.validateLanguage(langName, raw)
+	}
+	sort.Strings(definedLanguages)
+	v.validateClaims(languages, definedLanguages)
+	return v.sortedProblems()
+}
+
+// LintLanguages prints the problems of a languages configuration file, or of the default languages if the file name
+// is empty, and returns how many were found. Problems of the default languages are located in --print-languages.
+func LintLanguages(fileName string) int {
+	content := []byte{}
+	base := Languages
+	if fileName == "" {
+		fileName = "default languages"
+		base = map[string]LanguageInfo{}
+		var err error
+		if content, err = json.MarshalIndent(Languages, "", "  "); err != nil {
+			logger.LogStackTraceAndExit(err)
+		}
+	} else {
+		var err error
+		if content, err = os.ReadFile(fileName); err != nil {
+			logger.LogStackTraceAndExit(err)
+		}
+	}
+
+	problems := ValidateLanguages(content, base)
+	for _, problem := range problems {
+		fmt.Println(fileName + ":" + problem.String())
+	}
+	if len(problems) == 0 {
+		logger.Info("No problems found in ", fileName)
+	} else {
+		logger.Info(len(problems), " problems found in ", fileName)
+	}
+	return len(problems)
+}
+
+// locate records the offset of every value of the JSON content by its path, and reports the keys defined twice.
+// The content is known to be valid JSON.
+func (v *configValidator) locate(decoder *json.Decoder, path string) {
+	v.locations[path] = v.valueStart(int(decoder.InputOffset()))
+	token, err := decoder.Token()
+	if err != nil {
+		return
+	}
+	switch token {
+	case json.Delim('{'):
+		keys := map[string]bool{}
+		for decoder.More() {
+			keyOffset := v.valueStart(int(decoder.InputOffset()))
+			keyToken, _ := decoder.Token()
+			key, _ := keyToken.(string)
+			keyPath := key
+			if path != "" {
+				keyPath = path + "." + key
+			}
+			if keys[key] {
+				v.reportAt(keyOffset, keyPath, "is defined more than once, only the last definition is used")
+			}
+			keys[key] = true
+			v.locate(decoder, keyPath)
+		}
+		decoder.Token()
+	case json.Delim('['):
+		for index := 0; decoder.More(); index++ {
+			v.locate(decoder, fmt.Sprintf("%s[%d]", path, index))
+		}
+		decoder.Token()
+	}
+}
+
+// returns the offset of the next value or key, skipping the whitespace and separators before it
+func (v *configValidator) valueStart(offset int) int {
+	for offset < len(v.content) && strings.IndexByte(" \t\r\n:,", v.content[offset]) != -1 {
+		offset++
+	}
+	return offset
+}
+
+// report records a problem of the value at the path, located at the closest value of the path that was found
+func (v *configValidator) report(path string, message string) {
+	for location := path; location != ""; {
+		if offset, found := v.locations[location]; found {
+			v.reportAt(offset, path, message)
+			return
+		}
+		location = location[:max(strings.LastIndexAny(location, ".["), 0)]
+	}
+	v.reportAt(0, path, message)
+}
+
+func (v *configValidator) reportAt(offset int, path string, message string) {
+	offset = min(offset, len(v.content))
+	line := 1 + bytes.Count(v.content[:offset], []byte("\n"))
+	column := 1 + offset - (bytes.LastIndexByte(v.content[:offset], '\n') + 1)
+	v.problems = append(v.problems, ConfigProblem{Path: path, Line: line, Column: column, Message: message})
+	v.offsets = append(v.offsets, offset)
+}
+
+// returns the problems in the order they appear in the content
+func (v *configValidator) sortedProblems() []ConfigProblem {
+	indexes := make([]int, len(v.problems))
+	for index := range indexes {
+		indexes[index] = index
+	}
+	sort.SliceStable(indexes, func(a, b int) bool {
+		return v.offsets[indexes[a]] < v.offsets[indexes[b]]
+	})
+	problems := []ConfigProblem{}
+	for _, index := range indexes {
+		problems = append(problems, v.problems[index])
+	}
+	return problems
+}
+
+// the JSON keys of a language, ex: Extensions
+var languageInfoKeys = func() []string {
+	keys := []string{}
+	languageInfoType := reflect.TypeOf(LanguageInfo{})
+	for index := 0; index < languageInfoType.NumField(); index++ {
+		if tag, found := languageInfoType.Field(index).Tag.Lookup("json"); found {
+			key, _, _ := strings.Cut(tag, ",")
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}()
+
+// validateLanguage checks the definition of a single language and returns it decoded as well as it could be
+func (v *configValidator) validateLanguage(langName string, raw json.RawMessage) LanguageInfo {
+	languageInfo := LanguageInfo{}
+	rawKeys := map[string]json.RawMessage{}
+	if err := json.Unmarshal(raw, &rawKeys); err != nil {
+		v.report(langName, "must be an object defining the language")
+		return languageInfo
+	}
+	for key := range rawKeys {
+		// keys are matched case insensitively when they are loaded
+		if slices.ContainsFunc(languageInfoKeys, func(known string) bool { return strings.EqualFold(key, known) }) {
+			continue
+		}
+		message := fmt.Sprintf("unknown key %q is ignored", key)
+		for _, known := range languageInfoKeys {
+			if strings.EqualFold(key+"s", known) || strings.EqualFold(key, known+"s") {
+				message = fmt.Sprintf("unknown key %q is ignored, did you mean %q?", key, known)
+			}
+		}
+		v.report(langName+"."+key, message)
+	}
+
+	var typeError *json.UnmarshalTypeError
+	err := json.Unmarshal(raw, &languageInfo)
+	if errors.As(err, &typeError) {
+		v.report(langName+"."+typeError.Field, fmt.Sprintf("must be a %s, not a JSON %s", typeError.Type, typeError.Value))
+	}
+
+	// a claim that could not be decoded is already reported
+	if err == nil && len(languageInfo.Extensions) == 0 && len(languageInfo.FileNames) == 0 && len(languageInfo.Interpreters) == 0 {
+		v.report(langName, "claims no extensions, file names or interpreters, so no file is counted as "+langName)
+	}
+	for index, extension := range languageInfo.Extensions {
+		if !strings.HasPrefix(extension, ".") || extension == "." {
+			v.report(fmt.Sprintf("%s.Extensions[%d]", langName, index), fmt.Sprintf("extension %q must start with a dot, ex: \".%s\"", extension, strings.TrimPrefix(extension, ".")))
+		}
+	}
+	for _, field := range []struct {
+		key    string
+		values []string
+	}{
+		{"LineComments", languageInfo.LineComments},
+		{"CommentExceptions", languageInfo.CommentExceptions},
+		{"StringDelimiters", languageInfo.StringDelimiters},
+		{"DocStrings", languageInfo.DocStrings},
+		{"Heredocs", languageInfo.Heredocs},
+		{"AmbiguousHeredocs", languageInfo.AmbiguousHeredocs},
+		{"IndicatorComments", languageInfo.IndicatorComments},
+		{"FreeFormatDirectives", languageInfo.FreeFormatDirectives},
+		{"FileNames", languageInfo.FileNames},
+		{"Interpreters", languageInfo.Interpreters},
+	} {
+		for index, value := range field.values {
+			if value == "" {
+				v.report(fmt.Sprintf("%s.%s[%d]", langName, field.key, index), "must not be empty")
+			}
+		}
+	}
+	for _, field := range []struct {
+		key   string
+		pairs [][]string
+	}{
+		{"MultiLineComments", languageInfo.MultiLineComments},
+		{"NestedComments", languageInfo.NestedComments},
+	} {
+		for index, pair := range field.pairs {
+			if len(pair) != 2 || pair[0] == "" || pair[1] == "" {
+				v.report(fmt.Sprintf("%s.%s[%d]", langName, field.key, index), `must be a pair of start and end tokens, ex: ["/*", "*/"]`)
+			}
+		}
+	}
+	for index, multiLineString := range languageInfo.MultiLineStrings {
+		if len(multiLineString) < 2 || len(multiLineString) > 3 || multiLineString[0] == "" || multiLineString[1] == "" {
+			v.report(fmt.Sprintf("%s.MultiLineStrings[%d]", langName, index), `must be [start, end] or [start, end, escape], ex: ["`+"`"+`", "`+"`"+`"]`)
+		}
+	}
+	for index, columns := range languageInfo.IgnoredColumns {
+		if len(columns) != 2 || columns[0] < 1 || columns[0] > columns[1] {
+			v.report(fmt.Sprintf("%s.IgnoredColumns[%d]", langName, index), "must be a range of columns [first, last] starting at column 1, ex: [1, 6]")
+		}
+	}
+	for index, operator := range languageInfo.AmbiguousHeredocs {
+		if !slices.Contains(languageInfo.Heredocs, operator) {
+			v.report(fmt.Sprintf("%s.AmbiguousHeredocs[%d]", langName, index), fmt.Sprintf("has no effect since %q is not one of the Heredocs", operator))
+		}
+	}
+	if languageInfo.IndicatorColumn < 0 {
+		v.report(langName+".IndicatorColumn", "must be a column number starting at 1")
+	}
+	if len(languageInfo.IndicatorComments) > 0 && languageInfo.IndicatorColumn == 0 {
+		v.report(langName+".IndicatorComments", "has no effect without an IndicatorColumn")
+	}
+	for _, field := range []struct {
+		key      string
+		patterns []string
+	}{
+		{"LineCommentPatterns", languageInfo.LineCommentPatterns},
+		{"BlankPatterns", languageInfo.BlankPatterns},
+		{"IgnorePatterns", languageInfo.IgnorePatterns},
+	} {
+		for index, pattern := range field.patterns {
+			if _, err := regexp.Compile(pattern); err != nil {
+				v.report(fmt.Sprintf("%s.%s[%d]", langName, field.key, index), "invalid regular expression, "+err.Error())
+			}
+		}
+	}
+	return languageInfo
+}
+
+// validateClaims reports the extensions, file names and interpreters of the defined languages that are claimed by
+// several languages when only one of them can be used. A language sharing an extension is only used if it has the
+// highest priority or a content heuristic chooses it, while file names and interpreters cannot be shared at all.
+func (v *configValidator) validateClaims(languages map[string]LanguageInfo, definedLanguages []string) {
+	for _, field := range []struct {
+		key    string
+		claims func(LanguageInfo) []string
+	}{
+		{"Extensions", func(languageInfo LanguageInfo) []string { return languageInfo.Extensions }},
+		{"FileNames", func(languageInfo LanguageInfo) []string { return languageInfo.FileNames }},
+		{"Interpreters", func(languageInfo LanguageInfo) []string { return languageInfo.Interpreters }},
+	} {
+		claimants := map[string][]string{}
+		for langName, languageInfo := range languages {
+			for _, value := range field.claims(languageInfo) {
+				if !slices.Contains(claimants[value], langName) {
+					claimants[value] = append(claimants[value], langName)
+				}
+			}
+		}
+		for _, langName := range definedLanguages {
+			for index, value := range field.claims(languages[langName]) {
+				if len(claimants[value]) < 2 || slices.Index(field.claims(languages[langName]), value) != index {
+					continue
+				}
+				path := fmt.Sprintf("%s.%s[%d]", langName, field.key, index)
+				if field.key != "Extensions" {
+					others := slices.DeleteFunc(slices.Clone(claimants[value]), func(other string) bool { return other == langName })
+					sort.Strings(others)
+					// the other language reports the same claim if it is defined too
+					if slices.ContainsFunc(others, func(other string) bool { return other < langName && slices.Contains(definedLanguages, other) }) {
+						continue
+					}
+					v.report(path, fmt.Sprintf("%q is also claimed by %s, which of them is used is random", value, strings.Join(others, ", ")))
+					continue
+				}
+				v.validateExtensionClaim(languages, definedLanguages, langName, value, claimants[value], path)
+			}
+		}
+	}
+}
+
+// reports the languages claiming the extension that are never used for it, ones that neither have the highest priority
+// nor a content heuristic choosing them. Each of them is reported once, where it or the language used instead is defined.
+func (v *configValidator) validateExtensionClaim(languages map[string]LanguageInfo, definedLanguages []string, langName string, extension string, claimants []string, path string) {
+	candidates := slices.Clone(claimants)
+	sort.Slice(candidates, func(a, b int) bool {
+		if languages[candidates[a]].Priority != languages[candidates[b]].Priority {
+			return languages[candidates[a]].Priority > languages[candidates[b]].Priority
+		}
+		return candidates[a] < candidates[b]
+	})
+	used := candidates[0]
+	for _, candidate := range candidates[1:] {
+		hasHeuristic := slices.ContainsFunc(extensionHeuristics[extension], func(h heuristic) bool { return h.langName == candidate })
+		if hasHeuristic {
+			continue
+		}
+		// the claim is reported by the unused language if it is defined, otherwise by the language used instead
+		reporter := candidate
+		if !slices.Contains(definedLanguages, candidate) {
+			reporter = used
+		}
+		if reporter != langName {
+			continue
+		}
+		v.report(path, fmt.Sprintf("extension %q is claimed by both %s and %s, only %s is used. Set a higher Priority to choose the language", extension, used, candidate, used))
+	}
+}
@@ -25,6 +25,17 @@ type CLIArgs struct {
 	CsvFilePath                     string
 	HtmlReportsDirectoryPath        string
 	OverrideLanguagesConfigFilePath string
+	Workers                         int
+	DocStringsAsCode                bool
+	DisabledCodeAsComments          bool
+	NotebookMarkdownAsComments      bool
+	FallbackEncoding                string
+	IncludeGenerated                bool
+	IncludeVendored                 bool
+	VendoredFilePath                string
+	TestFilePath                    string
+	Deduplicate                     bool
+	FileMemoryLimit                 int
 }
 
 func CleanLocalFilePath(targetPath string) string {
@@ -46,6 +57,17 @@ func ParseArgsFromCLI() CLIArgs {
 	csvFilePathArg := flag.String("csv", "", "Path to dump results to a csv file, otherwise results are printed to standard out")
 	htmlReportsDirectoryPathArg := flag.String("html", "", "Path to dump HTML reports into a specified directory, otherwise HTML reports are not generated. Note this directory must already exist.")
 	overrideLanguageConfigFilePathArg := flag.String("override-languages", "", "Path to languages configuration to override the default configuration.")
+	deduplicateArg := flag.Bool("deduplicate", false, "Counts files with identical content once in the total and reports the groups of duplicate files. Hashes the content of every file scanned.")
+	disabledCodeAsCommentsArg := flag.Bool("disabled-code-as-comments", false, "Counts the lines of C-family languages disabled with #if 0 or #if false, including the #else branch of #if 1, as comments instead of code.")
+	docStringsAsCodeArg := flag.Bool("docstrings-as-code", false, "Counts docstrings, ex: Python's \"\"\"docstring\"\"\", as code instead of comments.")
+	notebookMarkdownAsCommentsArg := flag.Bool("notebook-markdown-as-comments", false, "Counts the markdown cells of Jupyter notebooks as comments. By default only code cells are counted.")
+	fileMemoryLimitArg := flag.Int("file-memory-limit", scanner.DefaultFileMemoryLimit/1024/1024, "Most memory in MB used to hold the content of a single file. Huge lines are scanned in chunks, but Jupyter notebooks larger than this are skipped and longer lines of Vue, Svelte and Astro components are truncated.")
+	fallbackEncodingArg := flag.String("fallback-encoding", "", "Encoding of files that are not valid UTF-8 and have no byte order mark - windows-1252, iso-8859-1, ebcdic-037. By default such files are read as is.")
+	includeGeneratedArg := flag.Bool("include-generated", false, "Counts minified and generated files, ex: app.min.js, in the total. By default they are reported separately and excluded from the total.")
+	includeVendoredArg := flag.Bool("include-vendored", false, "Counts files in vendored directories, ex: node_modules, in the total. By default they are reported separately and excluded from the total.")
+	testFilePathArg := flag.String("test-file-path", "", "Path to a file of patterns identifying test code in addition to the default conventions, one per line. Uses the same format as the ignore file, ex: *IT.java")
+	vendoredFilePathArg := flag.String("vendored-file-path", "", "Path to a file listing the names of directories containing third-party code, one per line, replacing the default list. Please see the README.md for the default list")
+	workersArg := flag.Int("workers", scanner.DefaultWorkerCount(), "Number of files to scan in parallel. Defaults to the number of usable CPUs.")
 
 	// parse the CLI arguments
 	flag.Parse()
@@ -62,6 +84,11 @@ func ParseArgsFromCLI() CLIArgs {
 	// Collect the remaining arguments
 	cliArgs := flag.Args()
 
+	// lint a languages configuration without running the tool, ex: 'go-cloc config lint languages.json'
+	if len(cliArgs) >= 2 && cliArgs[0] == "config" && cliArgs[1] == "lint" {
+		os.Exit(lintLanguages(cliArgs[2:], *overrideLanguageConfigFilePathArg))
+	}
+
 	// Ensure at least one argument
 	if len(cliArgs) < 1 {
 		logger.Error("Requires a path to the file or directory to scan as the first command line argument, ex: 'go-cloc file1.js'")
@@ -77,6 +104,17 @@ func ParseArgsFromCLI() CLIArgs {
 	csvFilePath := *csvFilePathArg
 	htmlReportsDirectoryPath := *htmlReportsDirectoryPathArg
 	overrideLanguageConfigFilePath := *overrideLanguageConfigFilePathArg
+	workers := *workersArg
+	docStringsAsCode := *docStringsAsCodeArg
+	disabledCodeAsComments := *disabledCodeAsCommentsArg
+	notebookMarkdownAsComments := *notebookMarkdownAsCommentsArg
+	fallbackEncoding := *fallbackEncodingArg
+	includeGenerated := *includeGeneratedArg
+	includeVendored := *includeVendoredArg
+	vendoredFilePath := *vendoredFilePathArg
+	testFilePath := *testFilePathArg
+	deduplicate := *deduplicateArg
+	fileMemoryLimit := *fileMemoryLimitArg
 
 	// Check if the directory exists
 	if htmlReportsDirectoryPath != "" {
@@ -88,6 +126,25 @@ func ParseArgsFromCLI() CLIArgs {
 		}
 	}
 
+	if workers < 1 {
+		logger.Error("The number of workers must be at least 1. Got: ", workers)
+		os.Exit(-1)
+	}
+
+	if fileMemoryLimit < 1 {
+		logger.Error("The file memory limit must be at least 1 MB. Got: ", fileMemoryLimit)
+		os.Exit(-1)
+	}
+
+	if fallbackEncoding != "" {
+		encoding, found := scanner.LookupFallbackEncoding(fallbackEncoding)
+		if !found {
+			logger.Error("Unsupported fallback encoding: ", fallbackEncoding, ". Supported encodings are windows-1252, iso-8859-1 and ebcdic-037")
+			os.Exit(-1)
+		}
+		fallbackEncoding = encoding
+	}
+
 	// set log level
 	logger.SetLogLevel(logger.ConvertStringToLogLevel(logLevel))
 	logger.SetOutput(os.Stdout)
@@ -100,6 +157,17 @@ func ParseArgsFromCLI() CLIArgs {
 	logger.Debug("html-reports-directory-path: ", htmlReportsDirectoryPath)
 	logger.Debug("ignore-file-path: ", ignoreFilePath)
 	logger.Debug("override-language-config-file-path: ", overrideLanguageConfigFilePath)
+	logger.Debug("workers: ", workers)
+	logger.Debug("docstrings-as-code: ", docStringsAsCode)
+	logger.Debug("disabled-code-as-comments: ", disabledCodeAsComments)
+	logger.Debug("notebook-markdown-as-comments: ", notebookMarkdownAsComments)
+	logger.Debug("fallback-encoding: ", fallbackEncoding)
+	logger.Debug("include-generated: ", includeGenerated)
+	logger.Debug("include-vendored: ", includeVendored)
+	logger.Debug("vendored-file-path: ", vendoredFilePath)
+	logger.Debug("test-file-path: ", testFilePath)
+	logger.Debug("deduplicate: ", deduplicate)
+	logger.Debug("file-memory-limit: ", fileMemoryLimit)
 
 	// Set file path to scan
 	localScanFilePath := CleanLocalFilePath(cliArgs[0])
@@ -115,12 +183,31 @@ func ParseArgsFromCLI() CLIArgs {
 		logger.Debug("Ignore Patterns: ", ignorePatterns)
 	}
 
+	// override vendored directories
+	if vendoredFilePath != "" {
+		logger.Debug("Overriding default vendored directories with ", vendoredFilePath)
+		scanner.LoadVendoredDirectories(vendoredFilePath)
+	}
+
+	// add test patterns
+	if testFilePath != "" {
+		logger.Debug("Adding test patterns from ", testFilePath)
+		scanner.LoadTestPatterns(testFilePath)
+	}
+
 	// override languages config
 	if overrideLanguageConfigFilePath != "" {
 		logger.Debug("Overriding default languages with ", overrideLanguageConfigFilePath)
 		scanner.LoadLanguages(overrideLanguageConfigFilePath)
 	}
 
+	scanner.Options.DocStringsAsCode = docStringsAsCode
+	scanner.Options.DisabledCodeAsComments = disabledCodeAsComments
+	scanner.Options.NotebookMarkdownAsComments = notebookMarkdownAsComments
+	scanner.Options.FallbackEncoding = fallbackEncoding
+	scanner.Options.HashContent = deduplicate
+	scanner.Options.FileMemoryLimit = fileMemoryLimit * 1024 * 1024
+
 	args := CLIArgs{
 		LogLevel:                        logLevel,
 		LocalScanFilePath:               localScanFilePath,
@@ -128,7 +215,31 @@ func ParseArgsFromCLI() CLIArgs {
 		CsvFilePath:                     csvFilePath,
 		HtmlReportsDirectoryPath:        htmlReportsDirectoryPath,
 		OverrideLanguagesConfigFilePath: overrideLanguageConfigFilePath,
+		Workers:                         workers,
+		DocStringsAsCode:                docStringsAsCode,
+		DisabledCodeAsComments:          disabledCodeAsComments,
+		NotebookMarkdownAsComments:      notebookMarkdownAsComments,
+		FallbackEncoding:                fallbackEncoding,
+		IncludeGenerated:                includeGenerated,
+		IncludeVendored:                 includeVendored,
+		VendoredFilePath:                vendoredFilePath,
+		TestFilePath:                    testFilePath,
+		Deduplicate:                     deduplicate,
+		FileMemoryLimit:                 fileMemoryLimit,
 	}
 
 	return args
 }
+
+// lints the languages configuration named by the argument or by --override-languages, the default languages if there
+// is neither, and returns the exit code of the command: 1 if problems were found
+func lintLanguages(args []string, overrideLanguageConfigFilePath string) int {
+	fileName := overrideLanguageConfigFilePath
+	if len(args) > 0 {
+		fileName = args[0]
+	}
+	if scanner.LintLanguages(fileName) > 0 {
+		return 1
+	}
+	return 0
+}